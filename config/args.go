@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Argument types supported in g.yaml
+const (
+	ArgString     = "string"
+	ArgInt        = "int"
+	ArgBool       = "bool"
+	ArgEnum       = "enum"
+	ArgPath       = "path"
+	ArgIdentifier = "identifier"
+)
+
+// Arg represents a generator argument. In g.yaml an argument is either a
+// plain name or an object describing how the value is validated.
+type Arg struct {
+	Name        string   `yaml:"name"`
+	Type        string   `yaml:"type"`
+	Default     string   `yaml:"default"`
+	Pattern     string   `yaml:"pattern"`
+	Description string   `yaml:"description"`
+	Required    *bool    `yaml:"required"`
+	Enum        []string `yaml:"enum"`
+	Variadic    bool     `yaml:"variadic"`
+}
+
+// UnmarshalYAML accepts either a plain argument name or a full object
+func (a *Arg) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*a = Arg{Name: name}
+		return nil
+	}
+
+	type plain Arg
+	if err := unmarshal((*plain)(a)); err != nil {
+		return err
+	}
+
+	if a.Name == "" {
+		return fmt.Errorf("argument is missing a name")
+	}
+
+	switch a.Type {
+	case "", ArgString, ArgInt, ArgBool, ArgPath, ArgIdentifier:
+	case ArgEnum:
+		if len(a.Enum) == 0 {
+			return fmt.Errorf("argument %q: enum type requires a list of values in enum", a.Name)
+		}
+	default:
+		return fmt.Errorf("argument %q: unknown type %q", a.Name, a.Type)
+	}
+
+	if a.Pattern != "" {
+		if _, err := regexp.Compile(a.Pattern); err != nil {
+			return fmt.Errorf("argument %q: invalid pattern: %w", a.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// IsRequired reports whether a value must be given for the argument.
+// Arguments are required unless they have a default or set required: false.
+func (a Arg) IsRequired() bool {
+	if a.Required != nil {
+		return *a.Required
+	}
+	return a.Default == ""
+}
+
+// Resolve validates value against the argument definition, returning the
+// default if no value was given. Values are converted to their type, so int
+// and bool arguments become numbers and booleans. A variadic argument takes
+// a list of values, each validated on its own, and resolves to a list.
+func (a Arg) Resolve(value any) (any, error) {
+	items, list := toItems(value)
+	if len(items) == 0 && a.Default != "" {
+		items = []string{a.Default}
+		if a.Variadic {
+			items = strings.Fields(a.Default)
+		}
+	}
+
+	if len(items) == 0 {
+		if a.IsRequired() {
+			return nil, fmt.Errorf("missing argument: %s", a.Name)
+		}
+		if a.Variadic {
+			return []any{}, nil
+		}
+		return "", nil
+	}
+
+	if !a.Variadic && (list || len(items) > 1) {
+		return nil, fmt.Errorf("invalid argument %s: expected a single value, got %v", a.Name, value)
+	}
+
+	resolved := make([]any, len(items))
+	for i, item := range items {
+		v, err := a.validate(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %s: %q %w", a.Name, item, err)
+		}
+		resolved[i] = v
+	}
+
+	if a.Variadic {
+		return resolved, nil
+	}
+	return resolved[0], nil
+}
+
+// toItems flattens a scalar or list value into strings, reporting whether
+// it was a list. Empty strings count as no value.
+func toItems(value any) ([]string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		if v == "" {
+			return nil, false
+		}
+		return []string{v}, false
+	case []string:
+		return v, true
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return items, true
+	default:
+		return []string{fmt.Sprint(v)}, false
+	}
+}
+
+// validate checks a single value and converts it to the argument's type
+func (a Arg) validate(value string) (any, error) {
+	var typed any = value
+
+	switch a.Type {
+	case ArgInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("is not an integer")
+		}
+		typed = n
+	case ArgBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("is not a boolean")
+		}
+		typed = b
+	case ArgEnum:
+		// The declared spelling is used, so config.js and templates see the
+		// same value whatever case it was given in
+		i := slices.IndexFunc(a.Enum, func(v string) bool { return strings.EqualFold(v, value) })
+		if i == -1 {
+			return nil, fmt.Errorf("is not one of: %s", strings.Join(a.Enum, ", "))
+		}
+		typed = a.Enum[i]
+	case ArgPath:
+		if !filepath.IsLocal(value) {
+			return nil, fmt.Errorf("is not a relative path inside the output directory")
+		}
+	case ArgIdentifier:
+		if !token.IsIdentifier(value) {
+			return nil, fmt.Errorf("is not a valid identifier")
+		}
+	}
+
+	if a.Pattern != "" {
+		re, err := regexp.Compile("^(?:" + a.Pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("cannot be checked, invalid pattern: %w", err)
+		}
+		if !re.MatchString(value) {
+			return nil, fmt.Errorf("does not match pattern %s", a.Pattern)
+		}
+	}
+
+	return typed, nil
+}
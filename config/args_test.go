@@ -0,0 +1,179 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestArg_UnmarshalYAML(t *testing.T) {
+	data := `
+generators:
+  - name: route
+    args:
+      - path
+      - name: method
+        type: enum
+        enum: [get, post]
+        default: get
+        description: HTTP method
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	args := cfg.Generators[0].Args
+	if len(args) != 2 {
+		t.Fatalf("got %d args, want 2", len(args))
+	}
+	if args[0].Name != "path" || !args[0].IsRequired() {
+		t.Errorf("plain arg = %+v, want required path", args[0])
+	}
+	if args[1].Name != "method" || args[1].Type != ArgEnum || args[1].Default != "get" || args[1].IsRequired() {
+		t.Errorf("object arg = %+v", args[1])
+	}
+
+	invalid := []string{
+		"args: [{type: string}]",
+		"args: [{name: x, type: float}]",
+		"args: [{name: x, type: enum}]",
+		"args: [{name: x, pattern: '['}]",
+	}
+	for _, data := range invalid {
+		var gen Generator
+		if err := yaml.Unmarshal([]byte(data), &gen); err == nil {
+			t.Errorf("Unmarshal(%q) expected error", data)
+		}
+	}
+}
+
+func TestArg_Resolve(t *testing.T) {
+	optional := false
+
+	tests := []struct {
+		name    string
+		arg     Arg
+		value   any
+		want    any
+		wantErr string
+	}{
+		{
+			name:  "plain",
+			arg:   Arg{Name: "name"},
+			value: "anything",
+			want:  "anything",
+		},
+		{
+			name:    "missing required",
+			arg:     Arg{Name: "name"},
+			wantErr: "missing argument: name",
+		},
+		{
+			name: "missing optional",
+			arg:  Arg{Name: "name", Required: &optional},
+			want: "",
+		},
+		{
+			name: "default",
+			arg:  Arg{Name: "name", Default: "fallback"},
+			want: "fallback",
+		},
+		{
+			name:  "int",
+			arg:   Arg{Name: "count", Type: ArgInt},
+			value: "12",
+			want:  12,
+		},
+		{
+			name:  "bool",
+			arg:   Arg{Name: "test", Type: ArgBool},
+			value: "false",
+			want:  false,
+		},
+		{
+			name:    "invalid int",
+			arg:     Arg{Name: "count", Type: ArgInt},
+			value:   "twelve",
+			wantErr: `invalid argument count: "twelve" is not an integer`,
+		},
+		{
+			name:    "invalid bool",
+			arg:     Arg{Name: "test", Type: ArgBool},
+			value:   "maybe",
+			wantErr: `invalid argument test: "maybe" is not a boolean`,
+		},
+		{
+			name:  "enum ignores case",
+			arg:   Arg{Name: "method", Type: ArgEnum, Enum: []string{"get", "post"}},
+			value: "GET",
+			want:  "get",
+		},
+		{
+			name:    "invalid enum",
+			arg:     Arg{Name: "method", Type: ArgEnum, Enum: []string{"get", "post"}},
+			value:   "GETT",
+			wantErr: `invalid argument method: "GETT" is not one of: get, post`,
+		},
+		{
+			name:    "path outside output",
+			arg:     Arg{Name: "dir", Type: ArgPath},
+			value:   "../elsewhere",
+			wantErr: `invalid argument dir: "../elsewhere" is not a relative path inside the output directory`,
+		},
+		{
+			name:    "invalid identifier",
+			arg:     Arg{Name: "funcName", Type: ArgIdentifier},
+			value:   "Posts-Edit",
+			wantErr: `invalid argument funcName: "Posts-Edit" is not a valid identifier`,
+		},
+		{
+			name:  "variadic",
+			arg:   Arg{Name: "sizes", Type: ArgInt, Variadic: true},
+			value: []string{"1", "2"},
+			want:  []any{1, 2},
+		},
+		{
+			name: "variadic default",
+			arg:  Arg{Name: "fields", Default: "id name", Variadic: true},
+			want: []any{"id", "name"},
+		},
+		{
+			name:    "invalid variadic item",
+			arg:     Arg{Name: "sizes", Type: ArgInt, Variadic: true},
+			value:   []string{"1", "two"},
+			wantErr: `invalid argument sizes: "two" is not an integer`,
+		},
+		{
+			name:    "list for single value",
+			arg:     Arg{Name: "name"},
+			value:   []string{"a", "b"},
+			wantErr: "invalid argument name: expected a single value, got [a b]",
+		},
+		{
+			name:    "pattern must match whole value",
+			arg:     Arg{Name: "path", Pattern: "/[a-z/:]*"},
+			value:   "/posts?x",
+			wantErr: `invalid argument path: "/posts?x" does not match pattern /[a-z/:]*`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.arg.Resolve(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Resolve() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// What a transform does when its file pattern matches no files
+const (
+	MissingSkip  = "skip"
+	MissingError = "error"
+	MissingSeed  = "seed"
+)
+
+// Transform runs a JavaScript function from config.js on every file matching
+// File. File is a template rendered with the generator config and may be a
+// glob pattern. Seed is the template a missing file is created from when
+// Missing is "seed".
+type Transform struct {
+	Function string `yaml:"function"`
+	File     string `yaml:"file"`
+	Missing  string `yaml:"missing"`
+	Seed     string `yaml:"seed"`
+}
+
+// Transforms is the list of transforms of a generator. In g.yaml each entry
+// is either an object describing one transform or a mapping of function
+// names to files, which skips missing files.
+type Transforms []Transform
+
+// UnmarshalYAML accepts both the object and the function mapping form
+func (ts *Transforms) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var entries []map[string]string
+	if err := unmarshal(&entries); err != nil {
+		return err
+	}
+
+	*ts = nil
+	for _, entry := range entries {
+		if _, ok := entry["function"]; !ok {
+			functions := make([]string, 0, len(entry))
+			for function := range entry {
+				functions = append(functions, function)
+			}
+			sort.Strings(functions)
+
+			for _, function := range functions {
+				*ts = append(*ts, Transform{Function: function, File: entry[function]})
+			}
+			continue
+		}
+
+		t := Transform{
+			Function: entry["function"],
+			File:     entry["file"],
+			Missing:  entry["missing"],
+			Seed:     entry["seed"],
+		}
+		for key := range entry {
+			switch key {
+			case "function", "file", "missing", "seed":
+			default:
+				return fmt.Errorf("transform %q: unknown field %q", t.Function, key)
+			}
+		}
+		if err := t.validate(); err != nil {
+			return err
+		}
+		*ts = append(*ts, t)
+	}
+
+	return nil
+}
+
+func (t Transform) validate() error {
+	if t.Function == "" || t.File == "" {
+		return fmt.Errorf("transform needs a function and a file")
+	}
+
+	switch t.Missing {
+	case "", MissingSkip, MissingError:
+	case MissingSeed:
+		if t.Seed == "" {
+			return fmt.Errorf("transform %q: missing: seed requires a seed", t.Function)
+		}
+	default:
+		return fmt.Errorf("transform %q: unknown missing policy %q, expected skip, error or seed", t.Function, t.Missing)
+	}
+
+	return nil
+}
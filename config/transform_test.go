@@ -0,0 +1,69 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestTransforms_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    Transforms
+		wantErr bool
+	}{
+		{
+			name: "function mapping",
+			yaml: "- addRoute: server.go\n- b: b.go\n  a: a.go\n",
+			want: Transforms{
+				{Function: "addRoute", File: "server.go"},
+				{Function: "a", File: "a.go"},
+				{Function: "b", File: "b.go"},
+			},
+		},
+		{
+			name: "object",
+			yaml: "- function: addRoute\n  file: internal/*/router.go\n  missing: error\n",
+			want: Transforms{
+				{Function: "addRoute", File: "internal/*/router.go", Missing: MissingError},
+			},
+		},
+		{
+			name: "seed",
+			yaml: "- function: addRoute\n  file: router.go\n  missing: seed\n  seed: package web\n",
+			want: Transforms{
+				{Function: "addRoute", File: "router.go", Missing: MissingSeed, Seed: "package web"},
+			},
+		},
+		{
+			name:    "seed without template",
+			yaml:    "- function: addRoute\n  file: router.go\n  missing: seed\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown missing policy",
+			yaml:    "- function: addRoute\n  file: router.go\n  missing: create\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			yaml:    "- function: addRoute\n  files: router.go\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Transforms
+			err := yaml.Unmarshal([]byte(tt.yaml), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalYAML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalYAML() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -19,9 +19,63 @@ type Config struct {
 
 // Generator represents each generator in the generators list
 type Generator struct {
-	Name       string              `yaml:"name"`
-	Args       []string            `yaml:"args"`
-	Transforms []map[string]string `yaml:"transforms"`
-	Use        []string            `yaml:"use"`
-	Post       []string            `yaml:"post"`
+	Name           string          `yaml:"name"`
+	Description    string          `yaml:"description"`
+	Args           []Arg           `yaml:"args"`
+	Transforms     Transforms      `yaml:"transforms"`
+	GoTransforms   []GoTransform   `yaml:"goTransforms"`
+	DataTransforms []DataTransform `yaml:"dataTransforms"`
+	Use            []Use           `yaml:"use"`
+	Post           []string        `yaml:"post"`
+
+	// Extends names a generator this one is based on. Its args, templates,
+	// config.js and transforms are inherited, and template files of this
+	// generator replace the base's files with the same path.
+	Extends string `yaml:"extends"`
+
+	// Delimiters replaces the {{ and }} action delimiters in template files,
+	// for generators whose output uses them, such as Helm charts
+	Delimiters []string `yaml:"delimiters"`
+
+	// Engine selects how template files are rendered: "go" for text/template
+	// (the default) or "js" for JavaScript template literals
+	Engine string `yaml:"engine"`
+
+	// When maps template paths, relative to the tpl directory, to conditions
+	// that must hold for them to be rendered. A path may be a file, a
+	// directory or a glob pattern, and a condition is a template pipeline
+	// such as ".withTests" or "eq .db \"postgres\"".
+	When map[string]string `yaml:"when"`
+}
+
+// Template engines
+const (
+	EngineGo = "go"
+	EngineJS = "js"
+)
+
+// GoTransform is a structural edit to a Go file. Exactly one of the action
+// fields is set, naming what the code is added to. Code is a template
+// rendered with the generator config.
+type GoTransform struct {
+	File         string `yaml:"file"`
+	AddImport    string `yaml:"addImport"`
+	AddMethod    string `yaml:"addMethod"`
+	AppendToFunc string `yaml:"appendToFunc"`
+	AddField     string `yaml:"addField"`
+	AddCase      string `yaml:"addCase"`
+	Switch       string `yaml:"switch"`
+	Code         string `yaml:"code"`
+}
+
+// DataTransform is a path based edit to a YAML or JSON file. Exactly one of
+// the action fields is set to the dotted path being edited, such as
+// "services.web.ports". Value is a template rendered with the generator
+// config and parsed as YAML, which includes JSON.
+type DataTransform struct {
+	File   string `yaml:"file"`
+	Set    string `yaml:"set"`
+	Append string `yaml:"append"`
+	Merge  string `yaml:"merge"`
+	Value  string `yaml:"value"`
 }
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+)
+
+// Use is a generator run by a composite generator. With maps arguments of
+// the generator to templates rendered with the composite's config, such as
+// {name: "{{ .funcName }}"}; other arguments are passed through.
+type Use struct {
+	Generator string            `yaml:"generator"`
+	With      map[string]string `yaml:"with"`
+}
+
+// UnmarshalYAML accepts either a plain generator name or a full object
+func (u *Use) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*u = Use{Generator: name}
+		return nil
+	}
+
+	type plain Use
+	if err := unmarshal((*plain)(u)); err != nil {
+		return err
+	}
+
+	if u.Generator == "" {
+		return fmt.Errorf("use entry is missing a generator")
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestUse_UnmarshalYAML(t *testing.T) {
+	data := `
+use:
+  - route
+  - generator: view
+    with:
+      name: "{{ .funcName }}"
+`
+	var gen Generator
+	if err := yaml.Unmarshal([]byte(data), &gen); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Use{
+		{Generator: "route"},
+		{Generator: "view", With: map[string]string{"name": "{{ .funcName }}"}},
+	}
+	if !reflect.DeepEqual(gen.Use, want) {
+		t.Errorf("Use = %+v, want %+v", gen.Use, want)
+	}
+
+	invalid := []string{
+		"use: [{with: {name: x}}]",
+		"use: [{generator: view, with: [name]}]",
+	}
+	for _, data := range invalid {
+		var gen Generator
+		if err := yaml.Unmarshal([]byte(data), &gen); err == nil {
+			t.Errorf("Unmarshal(%q) expected error", data)
+		}
+	}
+}
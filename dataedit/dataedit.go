@@ -0,0 +1,340 @@
+// Package dataedit makes path based edits to YAML and JSON files while
+// keeping their comments, key order and layout.
+package dataedit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the syntax of a data file
+type Format int
+
+const (
+	YAML Format = iota
+	JSON
+)
+
+// FormatOf returns the format of a file from its extension
+func FormatOf(name string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".json":
+		return JSON, nil
+	}
+	return 0, fmt.Errorf("unsupported data file %s, expected .yaml, .yml or .json", name)
+}
+
+// Set sets the value at path, creating missing mappings along the way
+func Set(src string, format Format, path, value string) (string, error) {
+	return edit(src, format, path, value, func(root, v *yaml.Node, keys []string) (bool, error) {
+		if len(keys) == 0 {
+			return false, errors.New("path is required")
+		}
+		last := len(keys) - 1
+		parent, err := lookup(root, keys[:last], yaml.MappingNode)
+		if err != nil {
+			return false, err
+		}
+		return set(parent, keys[last], v)
+	})
+}
+
+// Append appends the value to the list at path. Nothing changes if the list
+// already contains an equal value.
+func Append(src string, format Format, path, value string) (string, error) {
+	return edit(src, format, path, value, func(root, v *yaml.Node, keys []string) (bool, error) {
+		list, err := lookup(root, keys, yaml.SequenceNode)
+		if err != nil {
+			return false, err
+		}
+		if list.Kind != yaml.SequenceNode {
+			return false, fmt.Errorf("%s is not a list", strings.Join(keys, "."))
+		}
+
+		for _, item := range list.Content {
+			if equal(item, v) {
+				return false, nil
+			}
+		}
+		list.Content = append(list.Content, v)
+		return true, nil
+	})
+}
+
+// Merge merges the mapping value into the mapping at path. Nested mappings
+// are merged, other existing values are replaced.
+func Merge(src string, format Format, path, value string) (string, error) {
+	return edit(src, format, path, value, func(root, v *yaml.Node, keys []string) (bool, error) {
+		target, err := lookup(root, keys, yaml.MappingNode)
+		if err != nil {
+			return false, err
+		}
+		if target.Kind != yaml.MappingNode {
+			return false, fmt.Errorf("%s is not a mapping", strings.Join(keys, "."))
+		}
+		if v.Kind != yaml.MappingNode {
+			return false, errors.New("merge value is not a mapping")
+		}
+		return merge(target, v)
+	})
+}
+
+type editFunc func(root, value *yaml.Node, keys []string) (bool, error)
+
+// edit parses the source and value, applies fn to the first document and
+// writes the changed parts back into the source. The source is returned
+// unchanged if fn changed nothing.
+func edit(src string, format Format, path, value string, fn editFunc) (string, error) {
+	docs, err := decode(src)
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s: %w", formatName(format), err)
+	}
+	orig, err := decode(src)
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s: %w", formatName(format), err)
+	}
+
+	var v yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+		return "", fmt.Errorf("error parsing value: %w", err)
+	}
+	if len(v.Content) == 0 {
+		v = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	} else {
+		v = *v.Content[0]
+	}
+	if format == YAML {
+		blockStyle(&v)
+	}
+
+	root := docs[0].Content[0]
+	changed, err := fn(root, &v, splitPath(path))
+	if err != nil {
+		return "", err
+	}
+	if !changed {
+		return src, nil
+	}
+
+	// A source without data, such as an empty file, is written out whole
+	if orig[0].Content[0].Line == 0 {
+		if format == JSON {
+			return encodeJSON(root, src), nil
+		}
+		if src != "" && !strings.HasSuffix(src, "\n") {
+			src += "\n"
+		}
+		s := &splicer{format: format, indent: detectIndent(src)}
+		return src + s.block(root) + "\n", nil
+	}
+
+	return splice(src, format, orig, orig[0].Content[0], root), nil
+}
+
+func formatName(format Format) string {
+	if format == JSON {
+		return "JSON"
+	}
+	return "YAML"
+}
+
+// splitPath splits a dotted path such as "services.web.ports" into keys.
+// An empty path refers to the document root.
+func splitPath(path string) []string {
+	if path == "" || path == "." {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// lookup walks the keys from root, creating missing entries in mappings.
+// The final entry is created with the given kind.
+func lookup(node *yaml.Node, keys []string, kind yaml.Kind) (*yaml.Node, error) {
+	for i, key := range keys {
+		switch node.Kind {
+		case yaml.MappingNode:
+			next := mapValue(node, key)
+			if next == nil {
+				k := kind
+				if i < len(keys)-1 {
+					k = yaml.MappingNode
+				}
+				next = &yaml.Node{Kind: k, Tag: tagOf(k)}
+				node.Content = append(node.Content, scalar(key), next)
+			}
+			node = next
+		case yaml.SequenceNode:
+			idx, err := index(node, key)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", strings.Join(keys[:i+1], "."), err)
+			}
+			node = node.Content[idx]
+		default:
+			return nil, fmt.Errorf("%s is not a mapping or list", strings.Join(keys[:i], "."))
+		}
+	}
+	return node, nil
+}
+
+// set replaces the value of key in a mapping or the item at an index of a
+// list, keeping the comments of the value it replaces
+func set(parent *yaml.Node, key string, v *yaml.Node) (bool, error) {
+	var old *yaml.Node
+	switch parent.Kind {
+	case yaml.MappingNode:
+		old = mapValue(parent, key)
+		if old == nil {
+			parent.Content = append(parent.Content, scalar(key), v)
+			return true, nil
+		}
+	case yaml.SequenceNode:
+		idx, err := index(parent, key)
+		if err != nil {
+			return false, err
+		}
+		old = parent.Content[idx]
+	default:
+		return false, fmt.Errorf("cannot set %s on a scalar", key)
+	}
+
+	if equal(old, v) {
+		return false, nil
+	}
+	v.HeadComment, v.LineComment, v.FootComment = old.HeadComment, old.LineComment, old.FootComment
+	*old = *v
+	return true, nil
+}
+
+func merge(target, v *yaml.Node) (bool, error) {
+	changed := false
+	for i := 0; i < len(v.Content); i += 2 {
+		key, value := v.Content[i].Value, v.Content[i+1]
+
+		existing := mapValue(target, key)
+		if existing != nil && existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			c, err := merge(existing, value)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+			continue
+		}
+
+		c, err := set(target, key, value)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || c
+	}
+	return changed, nil
+}
+
+func mapValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func index(node *yaml.Node, key string) (int, error) {
+	idx, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("list index %q is not a number", key)
+	}
+	if idx < 0 {
+		idx += len(node.Content)
+	}
+	if idx < 0 || idx >= len(node.Content) {
+		return 0, fmt.Errorf("list index %s out of range", key)
+	}
+	return idx, nil
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func tagOf(kind yaml.Kind) string {
+	if kind == yaml.SequenceNode {
+		return "!!seq"
+	}
+	return "!!map"
+}
+
+// equal compares the data of two nodes, ignoring style and comments
+func equal(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return false
+	}
+	if a.Kind == yaml.ScalarNode && (a.ShortTag() != b.ShortTag() || a.Value != b.Value) {
+		return false
+	}
+	for i := range a.Content {
+		if !equal(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// blockStyle lays out an inserted value in block style, like the rest of
+// the file, even if it was written as JSON
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle
+	for _, c := range node.Content {
+		blockStyle(c)
+	}
+}
+
+// decode parses every document in the source. Empty source is a single
+// document holding an empty mapping.
+func decode(src string) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(strings.NewReader(src))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+
+	if len(docs) == 0 {
+		docs = append(docs, &yaml.Node{Kind: yaml.DocumentNode})
+	}
+	if len(docs[0].Content) == 0 {
+		docs[0].Content = append(docs[0].Content, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+	}
+	return docs, nil
+}
+
+// detectIndent returns the smallest indentation used in the source,
+// defaulting to two spaces
+func detectIndent(src string) int {
+	indent := 0
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		n := len(line) - len(trimmed)
+		if n > 0 && trimmed != "" && (indent == 0 || n < indent) {
+			indent = n
+		}
+	}
+	if indent == 0 {
+		return 2
+	}
+	return indent
+}
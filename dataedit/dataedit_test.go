@@ -0,0 +1,345 @@
+package dataedit
+
+import (
+	"testing"
+)
+
+const compose = `# compose file
+version: "3.8"
+
+services:
+  web:
+    image: nginx # the web server
+    ports:
+      - "80:80"
+
+  # database
+  db:
+    image: postgres
+`
+
+const pkg = `{
+  "name": "web",
+  "version": "1.0.0",
+  "scripts": {
+    "build": "vite build"
+  },
+  "files": ["dist"]
+}
+`
+
+func TestEdits(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		format Format
+		edit   func(src string, format Format) (string, error)
+		want   string
+	}{
+		{
+			name:   "append to yaml list",
+			src:    compose,
+			format: YAML,
+			edit: func(src string, format Format) (string, error) {
+				return Append(src, format, "services.web.ports", `"443:443"`)
+			},
+			want: `# compose file
+version: "3.8"
+
+services:
+  web:
+    image: nginx # the web server
+    ports:
+      - "80:80"
+      - "443:443"
+
+  # database
+  db:
+    image: postgres
+`,
+		},
+		{
+			name:   "set yaml key creating mappings",
+			src:    compose,
+			format: YAML,
+			edit: func(src string, format Format) (string, error) {
+				return Set(src, format, "services.db.environment.POSTGRES_DB", "app")
+			},
+			want: `# compose file
+version: "3.8"
+
+services:
+  web:
+    image: nginx # the web server
+    ports:
+      - "80:80"
+
+  # database
+  db:
+    image: postgres
+    environment:
+      POSTGRES_DB: app
+`,
+		},
+		{
+			name:   "set yaml value keeps comment",
+			src:    compose,
+			format: YAML,
+			edit: func(src string, format Format) (string, error) {
+				return Set(src, format, "services.web.image", "caddy")
+			},
+			want: `# compose file
+version: "3.8"
+
+services:
+  web:
+    image: caddy # the web server
+    ports:
+      - "80:80"
+
+  # database
+  db:
+    image: postgres
+`,
+		},
+		{
+			name:   "merge yaml mapping",
+			src:    compose,
+			format: YAML,
+			edit: func(src string, format Format) (string, error) {
+				return Merge(src, format, "services", "cache:\n  image: redis\nweb:\n  restart: always\n")
+			},
+			want: `# compose file
+version: "3.8"
+
+services:
+  web:
+    image: nginx # the web server
+    ports:
+      - "80:80"
+    restart: always
+
+  # database
+  db:
+    image: postgres
+  cache:
+    image: redis
+`,
+		},
+		{
+			name:   "append to json list",
+			src:    pkg,
+			format: JSON,
+			edit: func(src string, format Format) (string, error) {
+				return Append(src, format, "files", "README.md")
+			},
+			want: `{
+  "name": "web",
+  "version": "1.0.0",
+  "scripts": {
+    "build": "vite build"
+  },
+  "files": ["dist", "README.md"]
+}
+`,
+		},
+		{
+			name:   "merge json object",
+			src:    pkg,
+			format: JSON,
+			edit: func(src string, format Format) (string, error) {
+				return Merge(src, format, "scripts", `{"dev": "vite", "port": 3000}`)
+			},
+			want: `{
+  "name": "web",
+  "version": "1.0.0",
+  "scripts": {
+    "build": "vite build",
+    "dev": "vite",
+    "port": 3000
+  },
+  "files": ["dist"]
+}
+`,
+		},
+		{
+			name: "append to unindented yaml list",
+			src: `apiVersion: v1
+kind: Service
+spec:
+  ports:
+  - name: http
+    port: 80
+  selector:
+    app: web
+`,
+			format: YAML,
+			edit: func(src string, format Format) (string, error) {
+				return Append(src, format, "spec.ports", "{name: https, port: 443}")
+			},
+			want: `apiVersion: v1
+kind: Service
+spec:
+  ports:
+  - name: http
+    port: 80
+  - name: https
+    port: 443
+  selector:
+    app: web
+`,
+		},
+		{
+			name: "merge yaml keeps comment spacing and merge keys",
+			src: `x-base: &base
+  restart: always
+
+services:
+  web:   # the web
+    <<: *base
+    image: nginx    # pinned
+`,
+			format: YAML,
+			edit: func(src string, format Format) (string, error) {
+				return Merge(src, format, "services.web", "{image: caddy, volumes: [./data:/data]}")
+			},
+			want: `x-base: &base
+  restart: always
+
+services:
+  web:   # the web
+    <<: *base
+    image: caddy    # pinned
+    volumes:
+      - ./data:/data
+`,
+		},
+		{
+			name:   "append to yaml flow list",
+			src:    "name: web\ntags: [a, b]   # tags\n",
+			format: YAML,
+			edit: func(src string, format Format) (string, error) {
+				return Append(src, format, "tags", "c")
+			},
+			want: "name: web\ntags: [a, b, c]   # tags\n",
+		},
+		{
+			name:   "set yaml replaces block value",
+			src:    "script: |\n  echo one\n  echo two\nports:\n  - 80\nname: x\n",
+			format: YAML,
+			edit: func(src string, format Format) (string, error) {
+				return Set(src, format, "script", "echo three")
+			},
+			want: "script: echo three\nports:\n  - 80\nname: x\n",
+		},
+		{
+			name:   "set yaml in first document",
+			src:    "a: 1\n---\nb: 2\n",
+			format: YAML,
+			edit: func(src string, format Format) (string, error) {
+				return Set(src, format, "c", "3")
+			},
+			want: "a: 1\nc: 3\n---\nb: 2\n",
+		},
+		{
+			name:   "set json nested object",
+			src:    "{\n  \"a\": {\"b\": 1},\n  \"c\": 2\n}\n",
+			format: JSON,
+			edit: func(src string, format Format) (string, error) {
+				return Set(src, format, "d", `{"e": [1, 2]}`)
+			},
+			want: "{\n  \"a\": {\"b\": 1},\n  \"c\": 2,\n  \"d\": {\n    \"e\": [\n      1,\n      2\n    ]\n  }\n}\n",
+		},
+		{
+			name:   "append to compact json list",
+			src:    "{\"files\": [\"dist\", \"lib\"]}",
+			format: JSON,
+			edit: func(src string, format Format) (string, error) {
+				return Append(src, format, "files", "README.md")
+			},
+			want: "{\"files\": [\"dist\", \"lib\", \"README.md\"]}",
+		},
+		{
+			name:   "set yaml list item without trailing newline",
+			src:    "hosts:\n  - a.example.com # main\n  - b.example.com",
+			format: YAML,
+			edit: func(src string, format Format) (string, error) {
+				return Set(src, format, "hosts.0", "c.example.com")
+			},
+			want: "hosts:\n  - c.example.com # main\n  - b.example.com",
+		},
+		{
+			name:   "set in yaml file with only comments",
+			src:    "# settings\n",
+			format: YAML,
+			edit: func(src string, format Format) (string, error) {
+				return Set(src, format, "db.name", "app")
+			},
+			want: "# settings\ndb:\n  name: app\n",
+		},
+		{
+			name:   "set in empty json file",
+			src:    "",
+			format: JSON,
+			edit: func(src string, format Format) (string, error) {
+				return Set(src, format, "private", "true")
+			},
+			want: "{\n  \"private\": true\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.edit(tt.src, tt.format)
+			if err != nil {
+				t.Fatalf("edit error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("edit result = \n%s\nwant\n%s", got, tt.want)
+			}
+
+			// Applying the same edit again changes nothing
+			again, err := tt.edit(got, tt.format)
+			if err != nil {
+				t.Fatalf("second edit error = %v", err)
+			}
+			if again != got {
+				t.Errorf("edit is not idempotent:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestBlockScalar(t *testing.T) {
+	src := "script: |\n  echo one\n\n  echo two\nname: x\n"
+
+	got, err := Set(src, YAML, "name", "y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "script: |\n  echo one\n\n  echo two\nname: y\n"
+	if got != want {
+		t.Errorf("Set() = %q, want %q", got, want)
+	}
+}
+
+func TestEditErrors(t *testing.T) {
+	if _, err := Append(compose, YAML, "services.web.image", "x"); err == nil {
+		t.Error("Append() expected error for non-list target")
+	}
+	if _, err := Merge(compose, YAML, "services", "- x"); err == nil {
+		t.Error("Merge() expected error for non-mapping value")
+	}
+	if _, err := Set(compose, YAML, "services.web.ports.3", "x"); err == nil {
+		t.Error("Set() expected error for index out of range")
+	}
+	if _, err := Set(compose, YAML, "", "x"); err == nil {
+		t.Error("Set() expected error for empty path")
+	}
+	if _, err := Set("{", JSON, "a", "x"); err == nil {
+		t.Error("Set() expected error for invalid source")
+	}
+	if _, err := FormatOf("file.toml"); err == nil {
+		t.Error("FormatOf() expected error for unsupported extension")
+	}
+}
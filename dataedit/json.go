@@ -0,0 +1,97 @@
+package dataedit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// encodeJSON writes the node as JSON in the order it was parsed, indented
+// like the source. Numbers are written as they appeared in the source,
+// unless that is not valid JSON.
+func encodeJSON(node *yaml.Node, src string) string {
+	var b strings.Builder
+	writeJSON(&b, node, jsonIndent(src), "")
+	if src == "" || strings.HasSuffix(src, "\n") {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// jsonIndent returns the indentation step of a JSON source
+func jsonIndent(src string) string {
+	if strings.Contains(src, "\n\t") {
+		return "\t"
+	}
+	return strings.Repeat(" ", detectIndent(src))
+}
+
+// writeJSON writes a node with each entry on its own line, indented by
+// indent from prefix, or on a single line when indent is empty
+func writeJSON(b *strings.Builder, node *yaml.Node, indent, prefix string) {
+	open, close := "\n"+prefix+indent, "\n"+prefix
+	sep := ","
+	if indent == "" {
+		open, close, sep = "", "", ", "
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		b.WriteString("{")
+		for i := 0; i < len(node.Content); i += 2 {
+			if i > 0 {
+				b.WriteString(sep)
+			}
+			b.WriteString(open)
+			b.WriteString(quoteJSON(node.Content[i].Value))
+			b.WriteString(": ")
+			writeJSON(b, node.Content[i+1], indent, prefix+indent)
+		}
+		b.WriteString(close + "}")
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteString("[")
+		for i, item := range node.Content {
+			if i > 0 {
+				b.WriteString(sep)
+			}
+			b.WriteString(open)
+			writeJSON(b, item, indent, prefix+indent)
+		}
+		b.WriteString(close + "]")
+	case yaml.AliasNode:
+		writeJSON(b, node.Alias, indent, prefix)
+	default:
+		switch node.ShortTag() {
+		case "!!int", "!!float":
+			if json.Valid([]byte(node.Value)) {
+				b.WriteString(node.Value)
+			} else {
+				b.WriteString(quoteJSON(node.Value))
+			}
+		case "!!bool":
+			b.WriteString(strings.ToLower(node.Value))
+		case "!!null":
+			b.WriteString("null")
+		default:
+			b.WriteString(quoteJSON(node.Value))
+		}
+	}
+}
+
+func quoteJSON(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,533 @@
+package dataedit
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// textEdit replaces the source between start and end with text
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+// splicer rewrites only the parts of a source that an edit changed,
+// locating them with the positions of the nodes parsed from the source.
+// Everything else, including comments, quoting, flow style and the
+// indentation of lists, is copied as is.
+type splicer struct {
+	src    string
+	format Format
+
+	lineStarts []int
+	next       map[*yaml.Node]int
+
+	// indent is the indentation step of nested values written in block
+	// style, and indentless whether lists in mappings start at the column
+	// of their key, as in Kubernetes manifests
+	indent     int
+	indentless bool
+
+	edits []textEdit
+}
+
+// splice applies the differences between orig, parsed from src, and edited
+// to src
+func splice(src string, format Format, docs []*yaml.Node, orig, edited *yaml.Node) string {
+	s := &splicer{
+		src:    src,
+		format: format,
+		next:   map[*yaml.Node]int{},
+		indent: detectIndent(src),
+	}
+
+	s.lineStarts = []int{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			s.lineStarts = append(s.lineStarts, i+1)
+		}
+	}
+
+	// A node's text ends before the next node that is not part of it, or
+	// before the next document
+	end := len(src)
+	if len(docs) > 1 && len(docs[1].Content) > 0 {
+		end = s.offset(docs[1].Content[0])
+	}
+	s.walk(orig, end)
+	s.indentless = isIndentless(orig)
+
+	s.diff(orig, edited, nil, false)
+
+	sort.SliceStable(s.edits, func(i, j int) bool {
+		return s.edits[i].start > s.edits[j].start
+	})
+	// Edits at the same position were recorded innermost first, and are
+	// applied in reverse so they end up in that order
+	result := src
+	for i := 0; i < len(s.edits); {
+		j := i
+		for j < len(s.edits) && s.edits[j].start == s.edits[i].start {
+			j++
+		}
+		for k := j - 1; k >= i; k-- {
+			e := s.edits[k]
+			result = result[:e.start] + e.text + result[e.end:]
+		}
+		i = j
+	}
+	return result
+}
+
+// walk records where the text after each node begins: at the next node in
+// document order that is not part of it, or at end
+func (s *splicer) walk(root *yaml.Node, end int) {
+	var nodes []*yaml.Node
+	var after []int
+
+	var visit func(n *yaml.Node)
+	visit = func(n *yaml.Node) {
+		i := len(nodes)
+		nodes = append(nodes, n)
+		after = append(after, 0)
+		for _, c := range n.Content {
+			visit(c)
+		}
+		after[i] = len(nodes)
+	}
+	visit(root)
+
+	for i, n := range nodes {
+		s.next[n] = end
+		if after[i] < len(nodes) {
+			s.next[n] = s.offset(nodes[after[i]])
+		}
+	}
+}
+
+// offset returns the position in the source where a node starts
+func (s *splicer) offset(n *yaml.Node) int {
+	if n.Line == 0 || n.Line > len(s.lineStarts) {
+		return len(s.src)
+	}
+	off := s.lineStarts[n.Line-1]
+	for col := 1; col < n.Column && off < len(s.src); col++ {
+		_, size := utf8.DecodeRuneInString(s.src[off:])
+		off += size
+	}
+	return off
+}
+
+// column returns the zero based column of a position
+func (s *splicer) column(off int) int {
+	lineStart := strings.LastIndex(s.src[:off], "\n") + 1
+	return utf8.RuneCountInString(s.src[lineStart:off])
+}
+
+func (s *splicer) flow(n *yaml.Node) bool {
+	return s.format == JSON || n.Style&yaml.FlowStyle != 0
+}
+
+func (s *splicer) edit(start, end int, text string) {
+	s.edits = append(s.edits, textEdit{start: start, end: end, text: text})
+}
+
+// diff records the edits turning orig into edited. Mappings and lists that
+// only gained entries are extended, other changed nodes are replaced. key
+// is the key of orig when it is a mapping value, and flow whether orig is
+// inside a flow collection.
+func (s *splicer) diff(orig, edited, key *yaml.Node, flow bool) {
+	if equal(orig, edited) {
+		return
+	}
+
+	switch {
+	case orig.Kind == yaml.MappingNode && edited.Kind == yaml.MappingNode && keepsKeys(orig, edited):
+		var added []*yaml.Node
+		for i := 0; i < len(edited.Content); i += 2 {
+			k, v := edited.Content[i], edited.Content[i+1]
+			if j := keyIndex(orig, k.Value); j != -1 {
+				s.diff(orig.Content[j+1], v, orig.Content[j], s.flow(orig))
+			} else {
+				added = append(added, k, v)
+			}
+		}
+		if len(added) > 0 {
+			s.addPairs(orig, added)
+		}
+	case orig.Kind == yaml.SequenceNode && edited.Kind == yaml.SequenceNode && len(edited.Content) >= len(orig.Content):
+		for i := range orig.Content {
+			s.diff(orig.Content[i], edited.Content[i], nil, s.flow(orig))
+		}
+		if len(edited.Content) > len(orig.Content) {
+			s.addItems(orig, edited.Content[len(orig.Content):])
+		}
+	default:
+		s.replace(orig, edited, key, flow)
+	}
+}
+
+// keepsKeys reports whether edited has every key of orig
+func keepsKeys(orig, edited *yaml.Node) bool {
+	for i := 0; i < len(orig.Content); i += 2 {
+		if keyIndex(edited, orig.Content[i].Value) == -1 {
+			return false
+		}
+	}
+	return true
+}
+
+func keyIndex(node *yaml.Node, key string) int {
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// replace writes edited in place of orig
+func (s *splicer) replace(orig, edited, key *yaml.Node, flow bool) {
+	start := s.offset(orig)
+
+	switch {
+	case flow || s.flow(orig):
+		end := s.flowEnd(start)
+		multiline := strings.Contains(s.src[start:end], "\n")
+		s.edit(start, end, s.flowText(edited, s.lineIndent(start), multiline))
+	case orig.Kind == yaml.ScalarNode && edited.Kind == yaml.ScalarNode && !isBlockScalar(orig) && s.singleLine(orig):
+		s.edit(start, s.scalarEnd(start), s.scalar(edited))
+	case key != nil:
+		// Rewrite everything after the colon of the key
+		s.edit(s.colonEnd(key), s.blockEnd(orig), indentLines(pairValue(s, edited), spaces(s.column(s.offset(key)))))
+	default:
+		s.edit(start, s.blockEnd(orig), indentLines(s.block(edited), spaces(s.column(start))))
+	}
+}
+
+// addPairs appends key value pairs to a mapping
+func (s *splicer) addPairs(m *yaml.Node, pairs []*yaml.Node) {
+	if s.flow(m) {
+		var items []func(prefix string, multiline bool) string
+		for i := 0; i < len(pairs); i += 2 {
+			k, v := pairs[i], pairs[i+1]
+			items = append(items, func(prefix string, multiline bool) string {
+				return s.flowKey(k) + ": " + s.flowText(v, prefix, multiline)
+			})
+		}
+		s.insertFlow(m, items)
+		return
+	}
+
+	prefix := spaces(s.column(s.offset(m.Content[0])))
+	var b strings.Builder
+	for i := 0; i < len(pairs); i += 2 {
+		b.WriteString("\n" + prefix + indentLines(s.scalar(pairs[i])+":"+pairValue(s, pairs[i+1]), prefix))
+	}
+	end := s.blockEnd(m.Content[len(m.Content)-1])
+	s.edit(end, end, b.String())
+}
+
+// addItems appends items to a list, laid out like its last item
+func (s *splicer) addItems(seq *yaml.Node, items []*yaml.Node) {
+	if s.flow(seq) {
+		var texts []func(prefix string, multiline bool) string
+		for _, item := range items {
+			texts = append(texts, func(prefix string, multiline bool) string {
+				return s.flowText(item, prefix, multiline)
+			})
+		}
+		s.insertFlow(seq, texts)
+		return
+	}
+
+	last := seq.Content[len(seq.Content)-1]
+	start := s.offset(last)
+	dash := strings.LastIndex(s.src[:start], "-")
+	lead := spaces(s.column(dash)) + "-" + spaces(s.column(start)-s.column(dash)-1)
+	cont := spaces(s.column(start))
+
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString("\n" + lead + indentLines(s.block(item), cont))
+	}
+	end := s.blockEnd(last)
+	s.edit(end, end, b.String())
+}
+
+// insertFlow adds entries to a flow collection after its last entry,
+// separated like the entries before them
+func (s *splicer) insertFlow(coll *yaml.Node, items []func(prefix string, multiline bool) string) {
+	start := s.offset(coll)
+	end := s.flowEnd(start)
+
+	if len(coll.Content) == 0 {
+		var texts []string
+		for _, item := range items {
+			texts = append(texts, item("", false))
+		}
+		s.edit(end-1, end-1, strings.Join(texts, ", "))
+		return
+	}
+
+	// The separator is the space before the last entry, which starts at
+	// its key in a mapping
+	lastStart := s.offset(coll.Content[len(coll.Content)-1])
+	if coll.Kind == yaml.MappingNode {
+		lastStart = s.offset(coll.Content[len(coll.Content)-2])
+	}
+	sep := s.src[len(strings.TrimRight(s.src[:lastStart], " \t\r\n")):lastStart]
+	multiline := strings.Contains(sep, "\n")
+	prefix := sep[strings.LastIndex(sep, "\n")+1:]
+	if !multiline && sep == "" && len(coll.Content) <= 2 {
+		sep = " "
+	}
+
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString("," + sep + item(prefix, multiline))
+	}
+	at := s.flowEnd(s.offset(coll.Content[len(coll.Content)-1]))
+	s.edit(at, at, b.String())
+}
+
+// blockEnd returns where the text of a block node ends, leaving out the
+// blank lines and comments that follow it
+func (s *splicer) blockEnd(n *yaml.Node) int {
+	start := s.offset(n)
+	end := s.next[n]
+	if end > start {
+		end = strings.LastIndex(s.src[:end], "\n") + 1
+	}
+	if end <= start {
+		end = len(s.src)
+	}
+
+	lines := strings.Split(s.src[start:end], "\n")
+	count := len(lines)
+	for count > 1 {
+		line := strings.TrimSpace(lines[count-1])
+		if line != "" && !strings.HasPrefix(line, "#") && line != "---" && line != "..." {
+			break
+		}
+		count--
+	}
+	return start + len(strings.Join(lines[:count], "\n"))
+}
+
+func isBlockScalar(n *yaml.Node) bool {
+	return n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0
+}
+
+func (s *splicer) singleLine(n *yaml.Node) bool {
+	start := s.offset(n)
+	return !strings.Contains(s.src[start:s.scalarEnd(start)], "\n")
+}
+
+// scalarEnd returns the end of a quoted or plain scalar in block context
+func (s *splicer) scalarEnd(start int) int {
+	switch s.src[start] {
+	case '"', '\'':
+		return quotedEnd(s.src, start)
+	}
+	end := start
+	for end < len(s.src) && s.src[end] != '\n' && !strings.HasPrefix(s.src[end:], " #") {
+		end++
+	}
+	return len(strings.TrimRight(s.src[:end], " \t\r"))
+}
+
+// flowEnd returns the end of a node inside or forming a flow collection
+func (s *splicer) flowEnd(start int) int {
+	switch s.src[start] {
+	case '"', '\'':
+		return quotedEnd(s.src, start)
+	case '[', '{':
+		depth := 0
+		for i := start; i < len(s.src); i++ {
+			switch s.src[i] {
+			case '"', '\'':
+				i = quotedEnd(s.src, i) - 1
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return len(s.src)
+	}
+	end := start
+	for end < len(s.src) && !strings.ContainsRune(",]}\n", rune(s.src[end])) && !strings.HasPrefix(s.src[end:], " #") {
+		end++
+	}
+	return len(strings.TrimRight(s.src[:end], " \t\r"))
+}
+
+// quotedEnd returns the position after the quote closing the string that
+// starts at start
+func quotedEnd(src string, start int) int {
+	quote := src[start]
+	for i := start + 1; i < len(src); i++ {
+		switch {
+		case quote == '"' && src[i] == '\\':
+			i++
+		case src[i] == quote && quote == '\'' && i+1 < len(src) && src[i+1] == '\'':
+			i++
+		case src[i] == quote:
+			return i + 1
+		}
+	}
+	return len(src)
+}
+
+// colonEnd returns the position after the colon following a mapping key
+func (s *splicer) colonEnd(key *yaml.Node) int {
+	i := s.offset(key)
+	if s.src[i] == '"' || s.src[i] == '\'' {
+		i = quotedEnd(s.src, i)
+	}
+	for ; i < len(s.src); i++ {
+		if s.src[i] == ':' && (i+1 == len(s.src) || strings.ContainsRune(" \t\r\n", rune(s.src[i+1]))) {
+			return i + 1
+		}
+	}
+	return len(s.src)
+}
+
+// lineIndent returns the indentation of the line containing a position
+func (s *splicer) lineIndent(off int) string {
+	line := s.src[strings.LastIndex(s.src[:off], "\n")+1:]
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// block writes a value in block style, starting at column zero
+func (s *splicer) block(n *yaml.Node) string {
+	switch {
+	case n.Kind == yaml.MappingNode && len(n.Content) > 0:
+		lines := make([]string, 0, len(n.Content)/2)
+		for i := 0; i < len(n.Content); i += 2 {
+			lines = append(lines, s.scalar(n.Content[i])+":"+pairValue(s, n.Content[i+1]))
+		}
+		return strings.Join(lines, "\n")
+	case n.Kind == yaml.SequenceNode && len(n.Content) > 0:
+		lines := make([]string, 0, len(n.Content))
+		for _, item := range n.Content {
+			lines = append(lines, "- "+indentLines(s.block(item), "  "))
+		}
+		return strings.Join(lines, "\n")
+	case n.Kind == yaml.MappingNode:
+		return "{}"
+	case n.Kind == yaml.SequenceNode:
+		return "[]"
+	case n.Kind == yaml.AliasNode:
+		return "*" + n.Value
+	}
+	return s.scalar(n)
+}
+
+// pairValue writes the part of a mapping entry after the colon
+func pairValue(s *splicer, v *yaml.Node) string {
+	if len(v.Content) == 0 || v.Kind == yaml.AliasNode {
+		return " " + s.block(v)
+	}
+	indent := spaces(s.indent)
+	if v.Kind == yaml.SequenceNode && s.indentless {
+		indent = ""
+	}
+	return "\n" + indent + indentLines(s.block(v), indent)
+}
+
+// scalar writes a scalar in YAML, on a single line
+func (s *splicer) scalar(n *yaml.Node) string {
+	c := *n
+	c.HeadComment, c.LineComment, c.FootComment = "", "", ""
+	if strings.Contains(c.Value, "\n") {
+		c.Style = yaml.DoubleQuotedStyle
+	}
+	out, err := yaml.Marshal(&c)
+	if err != nil {
+		return c.Value
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// flowKey writes a key inside a flow mapping
+func (s *splicer) flowKey(k *yaml.Node) string {
+	if s.format == JSON {
+		return quoteJSON(k.Value)
+	}
+	return s.scalar(k)
+}
+
+// flowText writes a value inside a flow collection. JSON spanning several
+// lines is indented from prefix, the indentation of the entry's line.
+func (s *splicer) flowText(n *yaml.Node, prefix string, multiline bool) string {
+	if s.format == JSON {
+		var b strings.Builder
+		indent := ""
+		if multiline {
+			indent = jsonIndent(s.src)
+		}
+		writeJSON(&b, n, indent, prefix)
+		return b.String()
+	}
+
+	out, err := yaml.Marshal(flowStyle(n))
+	if err != nil {
+		return n.Value
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// flowStyle returns a copy of a node laid out in flow style
+func flowStyle(n *yaml.Node) *yaml.Node {
+	c := *n
+	c.HeadComment, c.LineComment, c.FootComment = "", "", ""
+	if c.Kind == yaml.MappingNode || c.Kind == yaml.SequenceNode {
+		c.Style |= yaml.FlowStyle
+	}
+	if strings.Contains(c.Value, "\n") {
+		c.Style = yaml.DoubleQuotedStyle
+	}
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = flowStyle(child)
+	}
+	return &c
+}
+
+// isIndentless reports whether the first block list that is a mapping
+// value starts at the column of its key
+func isIndentless(n *yaml.Node) bool {
+	var found, indentless bool
+
+	var visit func(n *yaml.Node)
+	visit = func(n *yaml.Node) {
+		for i := 0; i < len(n.Content) && !found; i++ {
+			c := n.Content[i]
+			if n.Kind == yaml.MappingNode && i%2 == 1 && c.Kind == yaml.SequenceNode && c.Style&yaml.FlowStyle == 0 {
+				found, indentless = true, c.Column == n.Content[i-1].Column
+				return
+			}
+			visit(c)
+		}
+	}
+	visit(n)
+
+	return indentless
+}
+
+// indentLines prefixes every line but the first
+func indentLines(text, prefix string) string {
+	return strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
+
+func spaces(n int) string {
+	return strings.Repeat(" ", n)
+}
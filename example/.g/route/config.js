@@ -43,25 +43,3 @@ function config({ method, path }) {
 
     return { method, path, routeFilename, viewFilename, funcName }
 }
-
-
-/**
- * @param {string} fileData
- * @param {ConfigObject} config
- * @returns {string} */
-function addRoute(fileData, config) {
-    let out = ''
-    let alreadyAdded = false
-    for (const line of fileData.split('\n')) {
-        if (line.includes(`e.${config.method}("${config.path}"`)) {
-            alreadyAdded = true
-        }
-
-        if (!alreadyAdded && line.endsWith('/* insert new routes here */')) {
-            out += `e.${config.method}("${config.path}", r.${config.funcName})\n`
-        }
-        out += line + '\n'
-    }
-
-    return out
-}
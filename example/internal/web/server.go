@@ -11,7 +11,6 @@ func NewServer() *echo.Echo {
 	r := &routes.Routes{}
 
 	e.GET("/posts/:id/edit", r.PostsEdit)
-	/* insert new routes here */
 
 	return e
 }
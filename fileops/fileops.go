@@ -1,12 +1,19 @@
 package fileops
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+
+	"github.com/aymanbagabas/go-udiff"
+	"github.com/aymanbagabas/go-udiff/myers"
+	"golang.org/x/term"
 )
 
 // Print writes to stderr with formatting
@@ -18,11 +25,6 @@ func Print(format string, a ...any) {
 
 // GoFmt formats a Go file using goimports and go fmt
 func GoFmt(targetPath string) error {
-	if os.Getenv("DRY_RUN") == "true" {
-		log.Println("DRY_RUN: formatting", targetPath)
-		return nil
-	}
-
 	if !strings.HasSuffix(targetPath, ".go") {
 		return nil
 	}
@@ -32,6 +34,16 @@ func GoFmt(targetPath string) error {
 		return nil
 	}
 
+	if overlay != nil {
+		return overlay.format(targetPath)
+	}
+
+	if journal != nil {
+		if err := journal.recordFile(targetPath); err != nil {
+			return err
+		}
+	}
+
 	cmd := exec.Command("goimports", "-w", targetPath)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -46,34 +58,145 @@ func GoFmt(targetPath string) error {
 	return nil
 }
 
+// FormatGo formats Go source with goimports, resolving imports as if the
+// source were already at targetPath. Non-Go files are returned unchanged.
+func FormatGo(targetPath string, data string) (string, error) {
+	if !strings.HasSuffix(targetPath, ".go") {
+		return data, nil
+	}
+
+	if _, err := exec.LookPath("gopls"); err != nil {
+		log.Println("gopls not found. Skipping imports and formatting.")
+		return data, nil
+	}
+
+	cmd := exec.Command("goimports", "-srcdir", filepath.Dir(targetPath))
+	cmd.Stdin = strings.NewReader(data)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error formatting file (%s): %w", targetPath, err)
+	}
+
+	return stdout.String(), nil
+}
+
 // MkdirP creates a directory and all necessary parent directories
 func MkdirP(targetPath string) error {
 	dir := filepath.Dir(targetPath)
 
-	if os.Getenv("DRY_RUN") == "true" {
-		log.Println("DRY_RUN: creating dir", dir)
+	if overlay != nil {
 		return nil
 	}
 
+	if journal != nil {
+		journal.recordDir(dir)
+	}
+
 	return os.MkdirAll(dir, os.ModePerm)
 }
 
 // WriteFile writes data to a file, creating the file if it does not exist
 func WriteFile(sourcePath string, data string) error {
-	if os.Getenv("DRY_RUN") == "true" {
-		log.Println("DRY_RUN: writing to", sourcePath)
-		log.Println("DRY_RUN: data", data)
+	if overlay != nil {
+		overlay.write(sourcePath, data)
 		return nil
 	}
 
+	if journal != nil {
+		if err := journal.recordFile(sourcePath); err != nil {
+			return err
+		}
+	}
+
 	return os.WriteFile(sourcePath, []byte(data), 0644)
 }
 
+// Chmod changes the permissions of a file. It does nothing in a dry run.
+func Chmod(path string, mode os.FileMode) error {
+	if overlay != nil {
+		return nil
+	}
+
+	if journal != nil {
+		if err := journal.recordFile(path); err != nil {
+			return err
+		}
+	}
+
+	return os.Chmod(path, mode)
+}
+
 // ReadFile reads the entire file and returns it as a string
 func ReadFile(path string) (string, error) {
+	if overlay != nil {
+		if data, ok := overlay.read(path); ok {
+			return data, nil
+		}
+	}
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return "", fmt.Errorf("error reading file: %w", err)
 	}
 	return string(data), nil
 }
+
+// Glob returns the files matching pattern in sorted order, including files
+// that only exist in the dry run overlay
+func Glob(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	}
+
+	if overlay != nil {
+		pattern = filepath.Clean(pattern)
+		for _, path := range overlay.order {
+			if ok, _ := filepath.Match(pattern, path); ok && !slices.Contains(matches, path) {
+				matches = append(matches, path)
+			}
+		}
+		sort.Strings(matches)
+	}
+
+	return matches, nil
+}
+
+// Diff returns a unified diff between two versions of a file, or an empty
+// string if they are identical. Binary files are only reported as changed.
+func Diff(oldLabel, newLabel, oldData, newData string) string {
+	if IsBinary(oldData) || IsBinary(newData) {
+		if oldData == newData {
+			return ""
+		}
+		return fmt.Sprintf("Binary files %s and %s differ\n", oldLabel, newLabel)
+	}
+
+	// Diff whole lines, as udiff.Unified diffs characters and then widens
+	// the edits, which shows unchanged lines next to a change as replaced
+	diff, err := udiff.ToUnified(oldLabel, newLabel, oldData, myers.ComputeEdits(oldData, newData), udiff.DefaultContextLines)
+	if err != nil {
+		return udiff.Unified(oldLabel, newLabel, oldData, newData)
+	}
+	return diff
+}
+
+// binarySniffLen is how much of a file IsBinary looks at
+const binarySniffLen = 8000
+
+// IsBinary reports whether data looks like a binary file rather than text,
+// by looking for a NUL byte near its start
+func IsBinary(data string) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	return strings.IndexByte(data, 0) != -1
+}
+
+// IsTerminal reports whether f is attached to an interactive terminal.
+// Other character devices, such as /dev/null, are not terminals.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -3,6 +3,8 @@ package fileops
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -76,12 +78,170 @@ func TestGoFmt(t *testing.T) {
 		t.Errorf("GoFmt() error = %v for non-go file", err)
 	}
 
-	// Test with DRY_RUN=true
-	os.Setenv("DRY_RUN", "true")
-	defer os.Unsetenv("DRY_RUN")
+	// Test in a dry run, with nothing written to the overlay
+	StartDryRun()
+	defer StopDryRun()
 
 	testGoFile := filepath.Join(tmpDir, "test.go")
 	if err := GoFmt(testGoFile); err != nil {
-		t.Errorf("GoFmt() error = %v with DRY_RUN=true", err)
+		t.Errorf("GoFmt() error = %v in dry run", err)
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	tmpDir := t.TempDir()
+	existing := filepath.Join(tmpDir, "existing.txt")
+	created := filepath.Join(tmpDir, "nested", "created.txt")
+	if err := os.WriteFile(existing, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := StartDryRun()
+	defer StopDryRun()
+
+	if err := MkdirP(created); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(created, "new\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(existing, "one\nthree\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reads see overlay content, disk is untouched
+	content, err := ReadFile(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != "one\nthree\n" {
+		t.Errorf("ReadFile() = %q, want overlay content", content)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "one\ntwo\n" {
+		t.Errorf("dry run modified file on disk: %q", data)
+	}
+	if _, err := os.Stat(filepath.Dir(created)); !os.IsNotExist(err) {
+		t.Error("dry run created directory on disk")
+	}
+
+	// Glob sees files created in the overlay
+	matches, err := Glob(filepath.Join(tmpDir, "*", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0] != created {
+		t.Errorf("Glob() = %v, want [%s]", matches, created)
+	}
+
+	diff := o.Diff()
+	for _, want := range []string{
+		"--- /dev/null\n+++ " + created,
+		"+new",
+		"--- " + existing,
+		"-two",
+		"+three",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("Diff() missing %q:\n%s", want, diff)
+		}
+	}
+}
+
+func TestJournal_Rollback(t *testing.T) {
+	tmpDir := t.TempDir()
+	existing := filepath.Join(tmpDir, "existing.txt")
+	created := filepath.Join(tmpDir, "nested", "dir", "created.txt")
+	if err := os.WriteFile(existing, []byte("original"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	j := Begin()
+	if err := WriteFile(existing, "changed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := MkdirP(created); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(created, "new"); err != nil {
+		t.Fatal(err)
+	}
+	j.End()
+
+	if err := j.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	content, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "original" {
+		t.Errorf("Rollback() content = %q, want original", content)
+	}
+	if info, _ := os.Stat(existing); info.Mode().Perm() != 0600 {
+		t.Errorf("Rollback() mode = %v, want 0600", info.Mode().Perm())
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "nested")); !os.IsNotExist(err) {
+		t.Error("Rollback() did not remove created directories")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	oldData := "func routes(e *echo.Echo, r *Routes) {\n\te.GET(\"/posts\", r.Posts)\n\te.GET(\"/posts/:id/edit\", r.PostsEdit)\n}\n"
+	newData := "func routes(e *echo.Echo, r *Routes) {\n\te.GET(\"/posts\", r.Posts)\n\te.GET(\"/posts/:id\", r.PostsShow)\n\te.GET(\"/posts/:id/edit\", r.PostsEdit)\n}\n"
+
+	diff := Diff("a/routes.go", "b/routes.go", oldData, newData)
+
+	// Unchanged lines only show up as context
+	var changed []string
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++") {
+			continue
+		}
+		if strings.HasPrefix(line, "-") || strings.HasPrefix(line, "+") {
+			changed = append(changed, line)
+		}
+	}
+	if want := []string{"+\te.GET(\"/posts/:id\", r.PostsShow)"}; !slices.Equal(changed, want) {
+		t.Errorf("Diff() changed lines = %q, want %q\n%s", changed, want, diff)
+	}
+	if !strings.Contains(diff, " \te.GET(\"/posts/:id/edit\", r.PostsEdit)\n") {
+		t.Errorf("Diff() = %q, want the edit route as context", diff)
+	}
+
+	if diff := Diff("a", "b", oldData, oldData); diff != "" {
+		t.Errorf("Diff() = %q for identical files", diff)
+	}
+}
+
+func TestDiffBinary(t *testing.T) {
+	text := "one\ntwo\n"
+	binary := "\x89PNG\x00\x01"
+
+	if IsBinary(text) {
+		t.Error("IsBinary() = true for text")
+	}
+	if !IsBinary(binary) {
+		t.Error("IsBinary() = false for binary data")
+	}
+
+	if diff := Diff("a.png", "b.png", binary, binary+"\x02"); diff != "Binary files a.png and b.png differ\n" {
+		t.Errorf("Diff() = %q for binary files", diff)
+	}
+	if diff := Diff("a.png", "b.png", binary, binary); diff != "" {
+		t.Errorf("Diff() = %q for identical binary files", diff)
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	// /dev/null is a character device, but not a terminal
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+
+	if IsTerminal(devNull) {
+		t.Error("IsTerminal() = true for /dev/null")
 	}
 }
@@ -0,0 +1,117 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Journal records the files and directories changed on disk so they can be
+// restored if a generator run fails. Changes made by post commands are not
+// recorded.
+type Journal struct {
+	entries []journalEntry
+	seen    map[string]bool
+}
+
+type journalEntry struct {
+	path    string
+	existed bool
+	data    []byte
+	mode    os.FileMode
+}
+
+var journal *Journal
+
+// Begin starts recording file changes in a new journal
+func Begin() *Journal {
+	journal = &Journal{
+		seen: map[string]bool{},
+	}
+	return journal
+}
+
+// End stops recording file changes. The journal can still be rolled back.
+func (j *Journal) End() {
+	if journal == j {
+		journal = nil
+	}
+}
+
+// Rollback restores modified files to their original contents and removes
+// files and directories created since Begin, most recent first
+func (j *Journal) Rollback() error {
+	j.End()
+
+	var errs []error
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		e := j.entries[i]
+		var err error
+		if e.existed {
+			err = os.WriteFile(e.path, e.data, e.mode)
+			if err == nil {
+				err = os.Chmod(e.path, e.mode)
+			}
+		} else if err = os.Remove(e.path); os.IsNotExist(err) {
+			err = nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error restoring %s: %w", e.path, err))
+		}
+	}
+
+	j.entries = nil
+	return errors.Join(errs...)
+}
+
+// recordFile saves the current state of path before it is first modified
+func (j *Journal) recordFile(path string) error {
+	path = filepath.Clean(path)
+	if j.seen[path] {
+		return nil
+	}
+	j.seen[path] = true
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		j.entries = append(j.entries, journalEntry{path: path})
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error recording %s: %w", path, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error recording %s: %w", path, err)
+	}
+
+	j.entries = append(j.entries, journalEntry{
+		path:    path,
+		existed: true,
+		data:    data,
+		mode:    info.Mode().Perm(),
+	})
+	return nil
+}
+
+// recordDir records every missing directory up to and including dir, in
+// the order they will be created
+func (j *Journal) recordDir(dir string) {
+	var missing []string
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil || j.seen[d] {
+			break
+		}
+		missing = append(missing, d)
+		if d == filepath.Dir(d) {
+			break
+		}
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		j.seen[missing[i]] = true
+		j.entries = append(j.entries, journalEntry{path: missing[i]})
+	}
+}
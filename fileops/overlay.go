@@ -0,0 +1,109 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Overlay is an in-memory view of the output tree used for dry runs.
+// Writes land in the overlay instead of on disk and reads see them.
+type Overlay struct {
+	files    map[string]string
+	original map[string]*string
+	order    []string
+	commands []Command
+}
+
+// Command is a shell command that would have been run during a dry run
+type Command struct {
+	Dir string
+	Cmd string
+}
+
+var overlay *Overlay
+
+// StartDryRun activates a new overlay. All subsequent file operations are
+// captured in memory until StopDryRun is called.
+func StartDryRun() *Overlay {
+	overlay = &Overlay{
+		files:    map[string]string{},
+		original: map[string]*string{},
+	}
+	return overlay
+}
+
+// StopDryRun deactivates the current overlay
+func StopDryRun() {
+	overlay = nil
+}
+
+// DryRun returns the active overlay, or nil if not in a dry run
+func DryRun() *Overlay {
+	return overlay
+}
+
+// AddCommand records a shell command that would have been run
+func (o *Overlay) AddCommand(dir, cmd string) {
+	o.commands = append(o.commands, Command{Dir: dir, Cmd: cmd})
+}
+
+// Commands returns the shell commands recorded during the dry run
+func (o *Overlay) Commands() []Command {
+	return o.commands
+}
+
+// Files returns the paths written during the dry run, in order of first write
+func (o *Overlay) Files() []string {
+	return o.order
+}
+
+// Diff returns a unified diff for every file written during the dry run.
+// Files that end up unchanged are omitted.
+func (o *Overlay) Diff() string {
+	var out strings.Builder
+	for _, path := range o.order {
+		oldLabel, oldData := path, ""
+		if orig := o.original[path]; orig != nil {
+			oldData = *orig
+		} else {
+			oldLabel = "/dev/null"
+		}
+
+		out.WriteString(Diff(oldLabel, path, oldData, o.files[path]))
+	}
+	return out.String()
+}
+
+func (o *Overlay) read(path string) (string, bool) {
+	data, ok := o.files[filepath.Clean(path)]
+	return data, ok
+}
+
+func (o *Overlay) write(path string, data string) {
+	path = filepath.Clean(path)
+	if _, ok := o.files[path]; !ok {
+		o.order = append(o.order, path)
+		if orig, err := os.ReadFile(path); err == nil {
+			s := string(orig)
+			o.original[path] = &s
+		}
+	}
+	o.files[path] = data
+}
+
+// format formats the overlay content of a Go file in place
+func (o *Overlay) format(targetPath string) error {
+	data, ok := o.read(targetPath)
+	if !ok {
+		return nil
+	}
+
+	formatted, err := FormatGo(targetPath, data)
+	if err != nil {
+		return err
+	}
+
+	o.write(targetPath, formatted)
+	return nil
+}
@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.quinn.io/g/config"
+)
+
+// ParseArgs maps command line arguments onto the generator's argument
+// definitions. Values can be given positionally, in definition order, or as
+// --name value (or --name=value) flags. A trailing variadic argument collects
+// the remaining positional values, or repeated flags, into a list.
+func ParseArgs(defs []config.Arg, argv []string) (map[string]any, error) {
+	byName := map[string]config.Arg{}
+	for i, def := range defs {
+		if def.Variadic && i != len(defs)-1 {
+			return nil, fmt.Errorf("variadic argument %s must be the last argument", def.Name)
+		}
+		byName[def.Name] = def
+	}
+
+	values := map[string]any{}
+	var positional []string
+
+	for i := 0; i < len(argv); i++ {
+		arg := argv[i]
+		if arg == "--" {
+			positional = append(positional, argv[i+1:]...)
+			break
+		}
+		if !isFlag(arg) {
+			positional = append(positional, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		def, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown flag: %s", arg)
+		}
+
+		if !hasValue {
+			if def.Type == config.ArgBool {
+				value = "true"
+			} else if i+1 < len(argv) {
+				i++
+				value = argv[i]
+			} else {
+				return nil, fmt.Errorf("flag needs a value: %s", arg)
+			}
+		}
+
+		if def.Variadic {
+			prev, _ := values[name].([]string)
+			values[name] = append(prev, value)
+			continue
+		}
+		if _, ok := values[name]; ok {
+			return nil, fmt.Errorf("flag given more than once: %s", arg)
+		}
+		values[name] = value
+	}
+
+	// Fill the remaining arguments from positional values, in order
+	for _, def := range defs {
+		if len(positional) == 0 {
+			break
+		}
+		if _, ok := values[def.Name]; ok {
+			continue
+		}
+		if def.Variadic {
+			values[def.Name] = positional
+			positional = nil
+			break
+		}
+		values[def.Name] = positional[0]
+		positional = positional[1:]
+	}
+
+	if len(positional) > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	return values, nil
+}
+
+// isFlag reports whether arg looks like a flag rather than a value. Negative
+// numbers are treated as values.
+func isFlag(arg string) bool {
+	if !strings.HasPrefix(arg, "-") || arg == "-" {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err != nil
+}
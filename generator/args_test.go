@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"go.quinn.io/g/config"
+)
+
+func TestParseArgs(t *testing.T) {
+	defs := []config.Arg{
+		{Name: "method"},
+		{Name: "path"},
+		{Name: "auth", Type: config.ArgBool},
+		{Name: "fields", Variadic: true},
+	}
+
+	tests := []struct {
+		name    string
+		argv    []string
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name: "positional",
+			argv: []string{"get", "/posts"},
+			want: map[string]any{"method": "get", "path": "/posts"},
+		},
+		{
+			name: "flags",
+			argv: []string{"--path", "/posts/:id", "--method=get"},
+			want: map[string]any{"method": "get", "path": "/posts/:id"},
+		},
+		{
+			name: "flags and positional",
+			argv: []string{"--path", "/posts", "get"},
+			want: map[string]any{"method": "get", "path": "/posts"},
+		},
+		{
+			name: "bool flag without value",
+			argv: []string{"get", "/posts", "--auth"},
+			want: map[string]any{"method": "get", "path": "/posts", "auth": "true"},
+		},
+		{
+			name: "variadic positional",
+			argv: []string{"get", "/posts", "false", "title", "body"},
+			want: map[string]any{"method": "get", "path": "/posts", "auth": "false", "fields": []string{"title", "body"}},
+		},
+		{
+			name: "variadic flags",
+			argv: []string{"--fields", "title", "--fields", "body", "-1"},
+			want: map[string]any{"method": "-1", "fields": []string{"title", "body"}},
+		},
+		{
+			name: "after double dash",
+			argv: []string{"--", "--get"},
+			want: map[string]any{"method": "--get"},
+		},
+		{
+			name:    "unknown flag",
+			argv:    []string{"--verb", "get"},
+			wantErr: "unknown flag: --verb",
+		},
+		{
+			name:    "missing flag value",
+			argv:    []string{"--method"},
+			wantErr: "flag needs a value: --method",
+		},
+		{
+			name:    "repeated flag",
+			argv:    []string{"--method", "get", "--method", "post"},
+			wantErr: "flag given more than once: --method",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseArgs(defs, tt.argv)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ParseArgs() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseArgs() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseArgs(defs[:2], []string{"get", "/posts", "extra"}); err == nil || err.Error() != "unexpected arguments: extra" {
+		t.Errorf("ParseArgs() with extra arguments error = %v", err)
+	}
+
+	if _, err := ParseArgs([]config.Arg{{Name: "fields", Variadic: true}, {Name: "name"}}, nil); err == nil {
+		t.Error("ParseArgs() expected error for variadic argument that is not last")
+	}
+}
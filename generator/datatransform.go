@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"go.quinn.io/g/config"
+	"go.quinn.io/g/dataedit"
+	"go.quinn.io/g/fileops"
+	tpl "go.quinn.io/g/template"
+)
+
+// applyDataTransform renders the transform's path and value and applies the
+// edit to its YAML or JSON file. A missing file is an error.
+func applyDataTransform(processor *tpl.Processor, t config.DataTransform, outDir string, gConfig map[string]any) error {
+	file, err := processor.RenderPath("file", t.File, gConfig)
+	if err != nil {
+		return fmt.Errorf("[DATA TRANSFORM:%s] %w", t.File, err)
+	}
+
+	format, err := dataedit.FormatOf(file)
+	if err != nil {
+		return fmt.Errorf("[DATA TRANSFORM:%s] %w", file, err)
+	}
+
+	sourcePath := path.Join(outDir, file)
+	sourceData, err := fileops.ReadFile(sourcePath)
+	if err != nil {
+		return fmt.Errorf("[DATA TRANSFORM:%s] %w", file, err)
+	}
+
+	var actions []string
+	for action, target := range map[string]string{
+		"set":    t.Set,
+		"append": t.Append,
+		"merge":  t.Merge,
+	} {
+		if target != "" {
+			actions = append(actions, action)
+		}
+	}
+	if len(actions) != 1 {
+		sort.Strings(actions)
+		return fmt.Errorf("[DATA TRANSFORM:%s] expected exactly one action, got %v", file, actions)
+	}
+
+	dataPath, err := processor.Render(actions[0], t.Set+t.Append+t.Merge, gConfig)
+	if err != nil {
+		return fmt.Errorf("[DATA TRANSFORM:%s] %w", file, err)
+	}
+
+	value, err := processor.Render("value", t.Value, gConfig)
+	if err != nil {
+		return fmt.Errorf("[DATA TRANSFORM:%s] %w", file, err)
+	}
+
+	var result string
+	switch {
+	case t.Set != "":
+		result, err = dataedit.Set(sourceData, format, dataPath, value)
+	case t.Append != "":
+		result, err = dataedit.Append(sourceData, format, dataPath, value)
+	case t.Merge != "":
+		result, err = dataedit.Merge(sourceData, format, dataPath, value)
+	}
+	if err != nil {
+		return fmt.Errorf("[DATA TRANSFORM:%s] %s %s: %w", file, actions[0], dataPath, err)
+	}
+
+	if result == sourceData {
+		return nil
+	}
+
+	return fileops.WriteFile(sourcePath, result)
+}
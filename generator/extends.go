@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"go.quinn.io/g/config"
+)
+
+// ResolveExtends merges every generator that extends another with its base,
+// so it inherits the base's args, transforms and post commands. Templates
+// and config.js are looked up in the base when the generator runs.
+func ResolveExtends(generators []Generator) error {
+	resolved := map[string]bool{}
+
+	var resolve func(i int, chain []string) error
+	resolve = func(i int, chain []string) error {
+		g := &generators[i]
+		if g.Cfg.Extends == "" || resolved[g.Cmd] {
+			return nil
+		}
+		if slices.Contains(chain, g.Cmd) {
+			return fmt.Errorf("extends cycle: %s", strings.Join(append(chain, g.Cmd), " -> "))
+		}
+
+		base := slices.IndexFunc(generators, func(other Generator) bool {
+			return other.Cmd == g.Cfg.Extends
+		})
+		if base == -1 {
+			return fmt.Errorf("%s extends unknown generator %s", g.Cmd, g.Cfg.Extends)
+		}
+		if err := resolve(base, append(chain, g.Cmd)); err != nil {
+			return err
+		}
+
+		parent := generators[base]
+		g.base = &parent
+		g.Cfg = extend(parent.Cfg, g.Cfg)
+		resolved[g.Cmd] = true
+		return nil
+	}
+
+	var errs []error
+	for i := range generators {
+		if err := resolve(i, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// extend returns the config of a generator based on base. Lists such as
+// transforms run the base's entries first, and settings given by the
+// generator win over the base's.
+func extend(base, cfg config.Generator) config.Generator {
+	cfg.Args = extendArgs(base.Args, cfg.Args)
+	cfg.Transforms = slices.Concat(base.Transforms, cfg.Transforms)
+	cfg.GoTransforms = slices.Concat(base.GoTransforms, cfg.GoTransforms)
+	cfg.DataTransforms = slices.Concat(base.DataTransforms, cfg.DataTransforms)
+	cfg.Post = slices.Concat(base.Post, cfg.Post)
+
+	if cfg.Description == "" {
+		cfg.Description = base.Description
+	}
+	if len(cfg.Use) == 0 {
+		cfg.Use = base.Use
+	}
+	if len(cfg.Delimiters) == 0 {
+		cfg.Delimiters = base.Delimiters
+	}
+	if cfg.Engine == "" {
+		cfg.Engine = base.Engine
+	}
+
+	when := maps.Clone(base.When)
+	if when == nil {
+		when = map[string]string{}
+	}
+	maps.Copy(when, cfg.When)
+	if len(when) > 0 {
+		cfg.When = when
+	}
+
+	return cfg
+}
+
+// extendArgs replaces base args with the generator's args of the same name
+// and appends the others
+func extendArgs(base, args []config.Arg) []config.Arg {
+	result := slices.Clone(base)
+	for _, arg := range args {
+		i := slices.IndexFunc(result, func(a config.Arg) bool { return a.Name == arg.Name })
+		if i == -1 {
+			result = append(result, arg)
+		} else {
+			result[i] = arg
+		}
+	}
+	return result
+}
+
+// chain returns the generator and the generators it extends, starting with
+// the one furthest up
+func (g *Generator) chain() []*Generator {
+	var chain []*Generator
+	for gen := g; gen != nil; gen = gen.base {
+		chain = append([]*Generator{gen}, chain...)
+	}
+	return chain
+}
+
+// dir returns the generator's directory in .g
+func (g *Generator) dir() string {
+	return path.Join(g.rootDir, ".g", g.Cfg.Name)
+}
@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.quinn.io/g/config"
+)
+
+func TestResolveExtends(t *testing.T) {
+	gen := func(cmd, extends string, args ...string) Generator {
+		cfg := config.Generator{Name: cmd, Extends: extends}
+		for _, arg := range args {
+			cfg.Args = append(cfg.Args, config.Arg{Name: arg})
+		}
+		return New(cfg, cmd, "")
+	}
+
+	tests := []struct {
+		name       string
+		generators []Generator
+		wantArgs   map[string][]string
+		wantErr    string
+	}{
+		{
+			name: "inherits args",
+			generators: []Generator{
+				gen("handler", "", "name", "method"),
+				gen("custom", "handler", "path"),
+			},
+			wantArgs: map[string][]string{
+				"handler": {"name", "method"},
+				"custom":  {"name", "method", "path"},
+			},
+		},
+		{
+			name: "base defined later",
+			generators: []Generator{
+				gen("custom", "lib:handler"),
+				gen("lib:handler", "lib:base", "method"),
+				gen("lib:base", "", "name"),
+			},
+			wantArgs: map[string][]string{
+				"custom":      {"name", "method"},
+				"lib:handler": {"name", "method"},
+				"lib:base":    {"name"},
+			},
+		},
+		{
+			name: "unknown base",
+			generators: []Generator{
+				gen("custom", "missing"),
+			},
+			wantErr: "custom extends unknown generator missing",
+		},
+		{
+			name: "cycle",
+			generators: []Generator{
+				gen("a", "b"),
+				gen("b", "a"),
+			},
+			wantErr: "extends cycle: a -> b -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ResolveExtends(tt.generators)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveExtends() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveExtends() error = %v", err)
+			}
+
+			for _, g := range tt.generators {
+				var args []string
+				for _, arg := range g.Cfg.Args {
+					args = append(args, arg.Name)
+				}
+				if !reflect.DeepEqual(args, tt.wantArgs[g.Cmd]) {
+					t.Errorf("%s args = %v, want %v", g.Cmd, args, tt.wantArgs[g.Cmd])
+				}
+			}
+		})
+	}
+}
+
+func TestExtend(t *testing.T) {
+	base := config.Generator{
+		Description: "A handler",
+		Args:        []config.Arg{{Name: "name"}, {Name: "method", Default: "get"}},
+		Post:        []string{"go mod tidy"},
+		Engine:      config.EngineJS,
+		When:        map[string]string{"tests": ".withTests", "docs": ".withDocs"},
+	}
+	cfg := config.Generator{
+		Args: []config.Arg{{Name: "method", Default: "post"}},
+		Post: []string{"go test ./..."},
+		When: map[string]string{"docs": "false"},
+	}
+
+	got := extend(base, cfg)
+	want := config.Generator{
+		Description: "A handler",
+		Args:        []config.Arg{{Name: "name"}, {Name: "method", Default: "post"}},
+		Post:        []string{"go mod tidy", "go test ./..."},
+		Engine:      config.EngineJS,
+		When:        map[string]string{"tests": ".withTests", "docs": "false"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extend() = %+v, want %+v", got, want)
+	}
+
+	// The base is left unchanged
+	if base.Args[1].Default != "get" || len(base.Post) != 1 || base.When["docs"] != ".withDocs" {
+		t.Errorf("extend() modified the base: %+v", base)
+	}
+}
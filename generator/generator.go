@@ -1,12 +1,14 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
-	"text/template"
 
 	"go.quinn.io/g/config"
 	"go.quinn.io/g/fileops"
@@ -20,6 +22,25 @@ type Generator struct {
 	rootDir string
 	Cmd     string
 	Cfg     config.Generator
+
+	// base is the generator named by Cfg.Extends, set by ResolveExtends
+	base *Generator
+
+	// callers are the composite generators running this one through use
+	callers []string
+
+	// Namespace and Source identify the include the generator was loaded
+	// from. Local generators have an empty namespace.
+	Namespace string
+	Source    string
+
+	// File and Line locate the generator's entry in its g.yaml, for errors
+	File string
+	Line int
+
+	// Conflict decides what happens when a template would overwrite an
+	// existing file with different content
+	Conflict tpl.ConflictPolicy
 }
 
 // New creates a new generator instance
@@ -31,126 +52,265 @@ func New(cfg config.Generator, cmd string, rootDir string) Generator {
 	}
 }
 
-// Run executes the generator with the given name and configuration
-func (g *Generator) Run(generators []Generator, gConfig map[string]string, outDir string) (map[string]string, error) {
-	fileops.Print("Running generator: %s\n", g.Cfg.Name)
-	fileops.Print("Args: %v\n", g.Cfg.Args)
-	fileops.Print("Config: %v\n", gConfig)
+// RootDir returns the directory containing the generator's .g directory
+func (g *Generator) RootDir() string {
+	return g.rootDir
+}
 
-	if len(g.Cfg.Use) > 0 {
-		for _, gName := range g.Cfg.Use {
-			g, err := Find(generators, gName)
-			if err != nil {
-				return nil, fmt.Errorf("[USE:%s] error finding generator: %w", gName, err)
-			}
+// Usage returns the command followed by its arguments, e.g. "route [method] [path]"
+func (g *Generator) Usage() string {
+	usage := g.Cmd
+	for _, arg := range g.Cfg.Args {
+		if arg.Variadic {
+			usage += fmt.Sprintf(" [%s...]", arg.Name)
+		} else {
+			usage += fmt.Sprintf(" [%s]", arg.Name)
+		}
+	}
+	return usage
+}
+
+// Templates returns the paths of the generator's template files, relative
+// to its tpl directory
+func (g *Generator) Templates() ([]string, error) {
+	files, err := g.templateFiles()
+	if err != nil {
+		return nil, err
+	}
 
-			gConfigRes, err := g.Run(generators, gConfig, outDir)
+	var templates []string
+	for _, file := range files {
+		if !file.dir {
+			templates = append(templates, file.path)
+		}
+	}
+	return templates, nil
+}
+
+// templateFile is a file or directory in a generator's tpl directory
+type templateFile struct {
+	path   string
+	source string
+	dir    bool
+}
+
+// templateFiles lists the generator's templates in walk order. Templates of
+// the generator it extends are included, unless the generator has its own
+// file with the same path.
+func (g *Generator) templateFiles() ([]templateFile, error) {
+	files := map[string]templateFile{}
+	for _, gen := range g.chain() {
+		templateDir := path.Join(gen.dir(), "tpl")
+		err := filepath.WalkDir(templateDir, func(sourcePath string, d os.DirEntry, err error) error {
 			if err != nil {
-				return nil, fmt.Errorf("[USE:%s] error running generator : %w", gName, err)
+				return err
 			}
-
-			for k, v := range gConfigRes {
-				gConfig[k] = v
+			if sourcePath == templateDir {
+				return nil
 			}
+
+			templatePath := strings.TrimPrefix(sourcePath, templateDir+"/")
+			files[templatePath] = templateFile{path: templatePath, source: sourcePath, dir: d.IsDir()}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error listing templates: %w", err)
+		}
+	}
+
+	// Sorting with the separator lowest lists a directory's contents right
+	// after it, as filepath.WalkDir does
+	sorted := slices.Collect(maps.Values(files))
+	slices.SortFunc(sorted, func(a, b templateFile) int {
+		return strings.Compare(strings.ReplaceAll(a.path, "/", "\x00"), strings.ReplaceAll(b.path, "/", "\x00"))
+	})
+	return sorted, nil
+}
+
+// Run executes the generator with the given name and configuration. If the
+// run fails, files written so far are restored to their original state.
+func (g *Generator) Run(generators []Generator, gConfig map[string]any, outDir string) (map[string]any, error) {
+	journal := fileops.Begin()
+	gConfig, err := g.run(generators, gConfig, outDir)
+	journal.End()
+
+	if err != nil {
+		if rbErr := journal.Rollback(); rbErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("error rolling back: %w", rbErr))
 		}
+		return nil, err
+	}
+
+	return gConfig, nil
+}
 
-		return gConfig, nil
+// configureEngine sets up the template engine and delimiters declared by
+// the generator
+func (g *Generator) configureEngine(processor *tpl.Processor, vm *jsvm.VM) error {
+	switch g.Cfg.Engine {
+	case "", config.EngineGo:
+	case config.EngineJS:
+		if len(g.Cfg.Delimiters) > 0 {
+			return fmt.Errorf("delimiters cannot be used with the %s engine", config.EngineJS)
+		}
+		processor.SetEngine(vm.RenderTemplate)
+	default:
+		return fmt.Errorf("unknown template engine %q, expected %s or %s", g.Cfg.Engine, config.EngineGo, config.EngineJS)
 	}
 
-	// Validate required arguments
+	if len(g.Cfg.Delimiters) > 0 {
+		if len(g.Cfg.Delimiters) != 2 || g.Cfg.Delimiters[0] == "" || g.Cfg.Delimiters[1] == "" {
+			return fmt.Errorf("delimiters must be a left and a right delimiter, got %q", g.Cfg.Delimiters)
+		}
+		processor.SetDelims(g.Cfg.Delimiters[0], g.Cfg.Delimiters[1])
+	}
+
+	return nil
+}
+
+func (g *Generator) run(generators []Generator, gConfig map[string]any, outDir string) (map[string]any, error) {
+	fileops.Print("Running generator: %s\n", g.Cfg.Name)
+	var argNames []string
+	for _, arg := range g.Cfg.Args {
+		argNames = append(argNames, arg.Name)
+	}
+	fileops.Print("Args: %v\n", argNames)
+	fileops.Print("Config: %v\n", gConfig)
+
+	// Validate arguments, filling in defaults
 	for _, arg := range g.Cfg.Args {
-		if gConfig[arg] == "" {
-			return nil, fmt.Errorf("missing argument: %s", arg)
+		value, err := arg.Resolve(gConfig[arg.Name])
+		if err != nil {
+			return nil, err
 		}
+		gConfig[arg.Name] = value
 	}
 
-	templateDir := path.Join(g.rootDir, ".g", g.Cfg.Name, "tpl")
-	gConfigPath := path.Join(g.rootDir, ".g", g.Cfg.Name, "config.js")
+	templateDir := path.Join(g.dir(), "tpl")
 
-	// Process JavaScript configuration
+	// Process JavaScript configuration, starting with the config.js of the
+	// generators this one extends
 	vm := jsvm.New()
-	if err := vm.SetConfig(gConfig); err != nil {
+	for _, gen := range g.chain() {
+		if err := vm.SetConfig(gConfig); err != nil {
+			return nil, err
+		}
+
+		result, err := vm.RunConfigFile(path.Join(gen.dir(), "config.js"))
+		if err != nil {
+			return nil, err
+		}
+
+		// Merge JavaScript config with existing config
+		for k, v := range result {
+			gConfig[k] = v
+		}
+	}
+
+	// Helper functions from config.js are callable from templates
+	helpers, err := vm.Helpers()
+	if err != nil {
 		return nil, err
 	}
 
-	jsConfig, err := vm.RunConfigFile(gConfigPath)
+	// Process templates
+	processor := tpl.New(templateDir, outDir)
+	processor.Conflict = g.Conflict
+	processor.AddFuncs(helpers)
+
+	partials, err := g.partials(generators)
 	if err != nil {
 		return nil, err
 	}
+	processor.AddPartials(partials)
 
-	// Merge JavaScript config with existing config
-	for k, v := range jsConfig {
-		gConfig[k] = v
+	if err := g.configureEngine(processor, vm); err != nil {
+		return nil, err
 	}
 
-	// Process templates
-	processor := tpl.New(templateDir, outDir)
-	if err := filepath.WalkDir(templateDir, func(sourcePath string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
+	// Run the generators this one is composed of
+	for _, use := range g.Cfg.Use {
+		if err := g.runUse(generators, processor, use, gConfig, outDir); err != nil {
+			return nil, err
 		}
-		if d.IsDir() {
-			return nil
+	}
+
+	files, err := g.templateFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var skipped []string
+	for _, file := range files {
+		if slices.ContainsFunc(skipped, func(dir string) bool { return strings.HasPrefix(file.path, dir+"/") }) {
+			continue
 		}
 
-		templatePath := strings.Replace(sourcePath, templateDir+"/", "", 1)
-		targetPath, err := processor.ProcessPath(templatePath, gConfig)
+		ok, err := g.included(processor, file.path, gConfig)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("error processing templates: %w", err)
+		}
+		if !ok {
+			fileops.Print("Skipping %s\n", file.path)
+			if file.dir {
+				skipped = append(skipped, file.path)
+			}
+			continue
+		}
+		if file.dir {
+			continue
 		}
 
-		if err := processor.ProcessFile(sourcePath, targetPath, gConfig); err != nil {
-			return err
+		outputs, err := processor.Expand(file.path, gConfig)
+		if errors.Is(err, tpl.ErrSkipPath) {
+			fileops.Print("Skipping %s\n", file.path)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error processing templates: %w", err)
 		}
 
-		return fileops.GoFmt(targetPath)
-	}); err != nil {
-		return nil, fmt.Errorf("error processing templates: %w", err)
+		for _, out := range outputs {
+			if err := processor.ProcessFile(file.source, out.Target, out.Config); err != nil {
+				return nil, fmt.Errorf("error processing templates: %w", err)
+			}
+		}
 	}
 
 	// Process transforms
 	if len(g.Cfg.Transforms) > 0 {
 		fileops.Print("Running transforms.\n")
 		for _, transform := range g.Cfg.Transforms {
-			for jsFunction, f := range transform {
-				sourcePath := path.Join(outDir, f)
-				sourceData, err := fileops.ReadFile(sourcePath)
-				if err != nil {
-					fileops.Print("Error reading file: %s\n. Will not perform transformation %s.\n original error: %v\n", sourcePath, jsFunction, err)
-					continue
-				}
-
-				result, err := vm.RunTransform(jsFunction, sourceData, gConfig)
-				if err != nil {
-					return nil, err
-				}
-
-				if err := fileops.WriteFile(sourcePath, result); err != nil {
-					return nil, err
-				}
-
-				if err := fileops.GoFmt(sourcePath); err != nil {
-					return nil, err
-				}
+			if err := applyTransform(vm, processor, transform, outDir, gConfig); err != nil {
+				return nil, err
 			}
 		}
 	}
 
+	// Process Go transforms
+	for _, transform := range g.Cfg.GoTransforms {
+		if err := applyGoTransform(processor, transform, outDir, gConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	// Process data transforms
+	for _, transform := range g.Cfg.DataTransforms {
+		if err := applyDataTransform(processor, transform, outDir, gConfig); err != nil {
+			return nil, err
+		}
+	}
+
 	// Run post-generation commands
 	if len(g.Cfg.Post) > 0 {
 		runner := shell.New(outDir)
 		for _, post := range g.Cfg.Post {
-			tmpl, err := template.New("post").Parse(post)
+			cmd, err := processor.Render("post command", post, gConfig)
 			if err != nil {
-				return nil, fmt.Errorf("error parsing post command template: %w", err)
-			}
-
-			var cmd strings.Builder
-			if err := tmpl.Execute(&cmd, gConfig); err != nil {
-				return nil, fmt.Errorf("error executing post command template: %w", err)
+				return nil, err
 			}
 
-			if err := runner.Run(cmd.String()); err != nil {
+			if err := runner.Run(cmd); err != nil {
 				return nil, fmt.Errorf("error running post command: %w", err)
 			}
 		}
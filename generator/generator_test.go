@@ -3,9 +3,12 @@ package generator
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"go.quinn.io/g/config"
+	"go.quinn.io/g/fileops"
 )
 
 func TestGenerator_Run(t *testing.T) {
@@ -24,13 +27,13 @@ func TestGenerator_Run(t *testing.T) {
 	// Create generator instance
 	cfg := config.Generator{
 		Name: "test-gen",
-		Args: []string{"name"},
+		Args: []config.Arg{{Name: "name"}},
 	}
 	g := New(cfg, "test-gen", rootDir)
 
 	// Run generator
 	generators := []Generator{g}
-	_, err := g.Run(generators, map[string]string{
+	_, err := g.Run(generators, map[string]any{
 		"name": "World",
 	}, outDir)
 	if err != nil {
@@ -79,16 +82,16 @@ function transform(input, config) {
 	// Create generator instance
 	cfg := config.Generator{
 		Name: "test-gen",
-		Args: []string{"name"},
-		Transforms: []map[string]string{
-			{"transform": "test.txt"},
+		Args: []config.Arg{{Name: "name"}},
+		Transforms: config.Transforms{
+			{Function: "transform", File: "test.txt"},
 		},
 	}
 	g := New(cfg, "test-gen", rootDir)
 
 	// Run generator
 	generators := []Generator{g}
-	_, err := g.Run(generators, map[string]string{
+	_, err := g.Run(generators, map[string]any{
 		"name": "World",
 	}, outDir)
 	if err != nil {
@@ -123,14 +126,14 @@ func TestGenerator_RunWithPost(t *testing.T) {
 	// Create generator instance
 	cfg := config.Generator{
 		Name: "test-gen",
-		Args: []string{"name"},
+		Args: []config.Arg{{Name: "name"}},
 		Post: []string{"touch {{.name}}.flag"},
 	}
 	g := New(cfg, "test-gen", rootDir)
 
 	// Run generator
 	generators := []Generator{g}
-	_, err := g.Run(generators, map[string]string{
+	_, err := g.Run(generators, map[string]any{
 		"name": "test",
 	}, outDir)
 	if err != nil {
@@ -149,3 +152,795 @@ func must(t *testing.T, err error) {
 		t.Fatal(err)
 	}
 }
+
+func TestGenerator_RunDryRun(t *testing.T) {
+	// Create temporary test directory
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	outDir := filepath.Join(tmpDir, "out")
+
+	// Create test directory structure
+	must(t, os.MkdirAll(filepath.Join(rootDir, ".g", "test-gen", "tpl"), 0755))
+
+	// Create test template
+	tplContent := "Hello {{.name}}!"
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "test-gen", "tpl", "test.txt.tpl"), []byte(tplContent), 0644))
+
+	// Create generator instance
+	cfg := config.Generator{
+		Name: "test-gen",
+		Args: []config.Arg{{Name: "name"}},
+		Post: []string{"touch {{.name}}.flag"},
+	}
+	g := New(cfg, "test-gen", rootDir)
+
+	overlay := fileops.StartDryRun()
+	defer fileops.StopDryRun()
+
+	// Run generator
+	generators := []Generator{g}
+	_, err := g.Run(generators, map[string]any{
+		"name": "test",
+	}, outDir)
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+
+	// Verify nothing was written
+	if _, err := os.Stat(outDir); !os.IsNotExist(err) {
+		t.Error("Run() in dry run created output directory")
+	}
+
+	if diff := overlay.Diff(); !strings.Contains(diff, "+Hello test!") {
+		t.Errorf("Diff() = %q, want rendered template", diff)
+	}
+
+	if cmds := overlay.Commands(); len(cmds) != 1 || cmds[0].Cmd != "touch test.flag" {
+		t.Errorf("Commands() = %v, want [touch test.flag]", cmds)
+	}
+}
+
+func TestGenerator_RunRollback(t *testing.T) {
+	// Create temporary test directory
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	outDir := filepath.Join(tmpDir, "out")
+
+	// Create test directory structure
+	must(t, os.MkdirAll(filepath.Join(rootDir, ".g", "test-gen", "tpl", "nested"), 0755))
+	must(t, os.MkdirAll(outDir, 0755))
+
+	// Create test template and a file for the transform to modify
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "test-gen", "tpl", "nested", "test.txt.tpl"), []byte("Hello {{.name}}!"), 0644))
+	must(t, os.WriteFile(filepath.Join(outDir, "existing.txt"), []byte("original"), 0644))
+
+	configJS := `
+function config(input) {
+    return input;
+}
+
+function transform(input, config) {
+    return input + " transformed";
+}
+`
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "test-gen", "config.js"), []byte(configJS), 0644))
+
+	// Create generator instance with a failing post command
+	cfg := config.Generator{
+		Name: "test-gen",
+		Args: []config.Arg{{Name: "name"}},
+		Transforms: config.Transforms{
+			{Function: "transform", File: "existing.txt"},
+		},
+		Post: []string{"exit 1"},
+	}
+	g := New(cfg, "test-gen", rootDir)
+
+	// Run generator
+	generators := []Generator{g}
+	if _, err := g.Run(generators, map[string]any{
+		"name": "World",
+	}, outDir); err == nil {
+		t.Fatal("Run() expected error from post command")
+	}
+
+	// Verify the run was rolled back
+	if _, err := os.Stat(filepath.Join(outDir, "nested")); !os.IsNotExist(err) {
+		t.Error("Run() did not remove rendered files after failure")
+	}
+
+	content, err := os.ReadFile(filepath.Join(outDir, "existing.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "original" {
+		t.Errorf("Run() did not restore transformed file, got %q", content)
+	}
+}
+
+func TestGenerator_Templates(t *testing.T) {
+	rootDir := t.TempDir()
+	tplDir := filepath.Join(rootDir, ".g", "test-gen", "tpl")
+
+	must(t, os.MkdirAll(filepath.Join(tplDir, "nested"), 0755))
+	must(t, os.WriteFile(filepath.Join(tplDir, "[name].go.tpl"), nil, 0644))
+	must(t, os.WriteFile(filepath.Join(tplDir, "nested", "static.txt"), nil, 0644))
+
+	g := New(config.Generator{Name: "test-gen"}, "test-gen", rootDir)
+	templates, err := g.Templates()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"[name].go.tpl", "nested/static.txt"}
+	if !reflect.DeepEqual(templates, expected) {
+		t.Errorf("Templates() = %v, want %v", templates, expected)
+	}
+
+	// Generators without templates, such as compositions, have none
+	g = New(config.Generator{Name: "missing"}, "missing", rootDir)
+	if templates, err := g.Templates(); err != nil || templates != nil {
+		t.Errorf("Templates() = %v, %v, want no templates", templates, err)
+	}
+}
+
+func TestGenerator_RunWithStructuredConfig(t *testing.T) {
+	// Create temporary test directory
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	outDir := filepath.Join(tmpDir, "out")
+
+	// Create test directory structure
+	must(t, os.MkdirAll(filepath.Join(rootDir, ".g", "test-gen", "tpl"), 0755))
+
+	// Create test template that ranges over a list and checks a boolean
+	tplContent := `type {{.name}} struct {
+{{- range .fields}}
+	{{.name}} {{.type}}
+{{- end}}
+}
+{{- if .timestamps}} // with timestamps{{end}}`
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "test-gen", "tpl", "model.txt.tpl"), []byte(tplContent), 0644))
+
+	// Create test config.js
+	configJS := `
+function config(input) {
+    return {
+        name: input.name,
+        timestamps: input.timestamps,
+        fields: input.fields.map(f => {
+            const [name, type] = f.split(':')
+            return { name, type }
+        })
+    };
+}
+`
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "test-gen", "config.js"), []byte(configJS), 0644))
+
+	// Create generator instance
+	cfg := config.Generator{
+		Name: "test-gen",
+		Args: []config.Arg{
+			{Name: "name"},
+			{Name: "timestamps", Type: config.ArgBool},
+			{Name: "fields", Variadic: true},
+		},
+	}
+	g := New(cfg, "test-gen", rootDir)
+
+	// Run generator
+	generators := []Generator{g}
+	_, err := g.Run(generators, map[string]any{
+		"name":       "Post",
+		"timestamps": "true",
+		"fields":     []string{"ID:int", "Title:string"},
+	}, outDir)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Verify output
+	content, err := os.ReadFile(filepath.Join(outDir, "model.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "type Post struct {\n\tID int\n\tTitle string\n} // with timestamps"
+	if string(content) != expected {
+		t.Errorf("Run() output = %q, want %q", string(content), expected)
+	}
+}
+
+func TestGenerator_RunWithHelpers(t *testing.T) {
+	// Create temporary test directory
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	outDir := filepath.Join(tmpDir, "out")
+
+	// Create test directory structure
+	must(t, os.MkdirAll(filepath.Join(rootDir, ".g", "test-gen", "tpl"), 0755))
+
+	// Create test template calling a helper from config.js
+	tplContent := `{{ greet .name }}`
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "test-gen", "tpl", "test.txt.tpl"), []byte(tplContent), 0644))
+
+	configJS := `
+var helpers = {
+    greet: (name) => "Hello " + name + "!",
+    flag: (name) => convertCase('kebab', name) + ".flag",
+}
+
+function config(input) {
+    return input;
+}
+`
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "test-gen", "config.js"), []byte(configJS), 0644))
+
+	// Create generator instance
+	cfg := config.Generator{
+		Name: "test-gen",
+		Args: []config.Arg{{Name: "name"}},
+		Post: []string{"touch {{ flag .name }}"},
+	}
+	g := New(cfg, "test-gen", rootDir)
+
+	// Run generator
+	generators := []Generator{g}
+	_, err := g.Run(generators, map[string]any{
+		"name": "WorldMap",
+	}, outDir)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Verify output
+	content, err := os.ReadFile(filepath.Join(outDir, "test.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Hello WorldMap!"
+	if string(content) != expected {
+		t.Errorf("Run() output = %v, want %v", string(content), expected)
+	}
+
+	if _, err := os.Stat(filepath.Join(outDir, "world-map.flag")); os.IsNotExist(err) {
+		t.Error("Post command did not use helper")
+	}
+}
+
+func TestGenerator_RunWithGoTransforms(t *testing.T) {
+	// Create temporary test directory
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	outDir := filepath.Join(tmpDir, "out")
+
+	// Create test directory structure
+	must(t, os.MkdirAll(filepath.Join(rootDir, ".g", "test-gen", "tpl"), 0755))
+	must(t, os.MkdirAll(outDir, 0755))
+
+	server := `package web
+
+func NewServer() *Echo {
+	e := New()
+
+	return e
+}
+`
+	must(t, os.WriteFile(filepath.Join(outDir, "server.go"), []byte(server), 0644))
+
+	// Create generator instance
+	cfg := config.Generator{
+		Name: "test-gen",
+		Args: []config.Arg{{Name: "path"}},
+		GoTransforms: []config.GoTransform{
+			{File: "server.go", AddImport: "net/http"},
+			{File: "server.go", AppendToFunc: "NewServer", Code: `e.GET("{{ .path }}", {{ .path | pascal }})`},
+		},
+	}
+	g := New(cfg, "test-gen", rootDir)
+
+	// Running twice applies the edits once
+	generators := []Generator{g}
+	for i := 0; i < 2; i++ {
+		if _, err := g.Run(generators, map[string]any{
+			"path": "/posts",
+		}, outDir); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+
+	// Verify output
+	content, err := os.ReadFile(filepath.Join(outDir, "server.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `package web
+
+import "net/http"
+
+func NewServer() *Echo {
+	e := New()
+	e.GET("/posts", Posts)
+
+	return e
+}
+`
+	if string(content) != expected {
+		t.Errorf("Run() output = %v, want %v", string(content), expected)
+	}
+
+	// A transform with more than one action is rejected
+	g.Cfg.GoTransforms = []config.GoTransform{{File: "server.go", AddField: "A", AddMethod: "B"}}
+	if _, err := g.Run(generators, map[string]any{"path": "/posts"}, outDir); err == nil {
+		t.Error("Run() expected error for transform with two actions")
+	}
+}
+
+func TestGenerator_RunWithDataTransforms(t *testing.T) {
+	// Create temporary test directory
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	outDir := filepath.Join(tmpDir, "out")
+
+	// Create test directory structure
+	must(t, os.MkdirAll(filepath.Join(rootDir, ".g", "test-gen", "tpl"), 0755))
+	must(t, os.MkdirAll(outDir, 0755))
+
+	gYaml := `version: "1"
+generators:
+  # creates g.yaml
+  - name: init
+`
+	must(t, os.WriteFile(filepath.Join(outDir, "g.yaml"), []byte(gYaml), 0644))
+	must(t, os.WriteFile(filepath.Join(outDir, "package.json"), []byte("{\n  \"name\": \"web\"\n}\n"), 0644))
+
+	// Create generator instance
+	cfg := config.Generator{
+		Name: "test-gen",
+		Args: []config.Arg{{Name: "name"}},
+		DataTransforms: []config.DataTransform{
+			{File: "g.yaml", Append: "generators", Value: "name: {{ .name }}"},
+			{File: "package.json", Set: "scripts.{{ .name }}", Value: "{{ .name | quote }}"},
+		},
+	}
+	g := New(cfg, "test-gen", rootDir)
+
+	// Running twice applies the edits once
+	generators := []Generator{g}
+	for i := 0; i < 2; i++ {
+		if _, err := g.Run(generators, map[string]any{
+			"name": "route",
+		}, outDir); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+
+	// Verify output
+	content, err := os.ReadFile(filepath.Join(outDir, "g.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `version: "1"
+generators:
+  # creates g.yaml
+  - name: init
+  - name: route
+`
+	if string(content) != expected {
+		t.Errorf("Run() output = %v, want %v", string(content), expected)
+	}
+
+	content, err = os.ReadFile(filepath.Join(outDir, "package.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `{
+  "name": "web",
+  "scripts": {
+    "route": "route"
+  }
+}
+`
+	if string(content) != expected {
+		t.Errorf("Run() output = %v, want %v", string(content), expected)
+	}
+
+	// Only YAML and JSON files can be edited
+	g.Cfg.DataTransforms = []config.DataTransform{{File: "server.go", Set: "a"}}
+	if _, err := g.Run(generators, map[string]any{"name": "route"}, outDir); err == nil {
+		t.Error("Run() expected error for unsupported file")
+	}
+}
+
+func TestGenerator_RunWithGlobTransforms(t *testing.T) {
+	// Create temporary test directory
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	outDir := filepath.Join(tmpDir, "out")
+
+	// Create test directory structure
+	must(t, os.MkdirAll(filepath.Join(rootDir, ".g", "test-gen", "tpl", "[name]"), 0755))
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "test-gen", "tpl", "[name]", "router.txt.tpl"), []byte("{{ .name }}:"), 0644))
+	for _, svc := range []string{"users", "posts"} {
+		must(t, os.MkdirAll(filepath.Join(outDir, "internal", svc), 0755))
+		must(t, os.WriteFile(filepath.Join(outDir, "internal", svc, "router.txt"), []byte(svc+":"), 0644))
+	}
+
+	configJS := `
+function config(input) {
+	return input;
+}
+
+function addHandler(input, config) {
+	return input + " " + config.name;
+}
+`
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "test-gen", "config.js"), []byte(configJS), 0644))
+
+	// Create generator instance
+	cfg := config.Generator{
+		Name: "test-gen",
+		Args: []config.Arg{{Name: "name"}},
+		Transforms: config.Transforms{
+			{Function: "addHandler", File: "internal/*/router.txt"},
+			{Function: "addHandler", File: "internal/{{ .name }}/missing.txt"},
+			{Function: "addHandler", File: "{{ .name }}.txt", Missing: config.MissingSeed, Seed: "{{ .name }}:"},
+			{Function: "addHandler", File: "[name]/router.txt", Missing: config.MissingError},
+		},
+	}
+	g := New(cfg, "test-gen", rootDir)
+
+	// Run generator
+	generators := []Generator{g}
+	if _, err := g.Run(generators, map[string]any{
+		"name": "health",
+	}, outDir); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Verify output
+	for file, expected := range map[string]string{
+		"internal/users/router.txt": "users: health",
+		"internal/posts/router.txt": "posts: health",
+		"health.txt":                "health: health",
+		"health/router.txt":         "health: health",
+	} {
+		content, err := os.ReadFile(filepath.Join(outDir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expected {
+			t.Errorf("Run() %s = %v, want %v", file, string(content), expected)
+		}
+	}
+
+	// A missing target is an error when the transform says so
+	g.Cfg.Transforms = config.Transforms{
+		{Function: "addHandler", File: "internal/*/missing.txt", Missing: config.MissingError},
+	}
+	if _, err := g.Run(generators, map[string]any{"name": "health"}, outDir); err == nil {
+		t.Error("Run() expected error for missing transform target")
+	}
+
+	// Glob patterns cannot be seeded
+	g.Cfg.Transforms = config.Transforms{
+		{Function: "addHandler", File: "internal/*/missing.txt", Missing: config.MissingSeed, Seed: "x"},
+	}
+	if _, err := g.Run(generators, map[string]any{"name": "health"}, outDir); err == nil {
+		t.Error("Run() expected error for seeding a glob pattern")
+	}
+}
+
+func TestGenerator_RunWithConditionalTemplates(t *testing.T) {
+	// Create temporary test directory
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	outDir := filepath.Join(tmpDir, "out")
+	tplDir := filepath.Join(rootDir, ".g", "test-gen", "tpl")
+
+	// Create test templates
+	must(t, os.MkdirAll(filepath.Join(tplDir, "migrations"), 0755))
+	must(t, os.MkdirAll(filepath.Join(tplDir, "[docsDir]"), 0755))
+	for _, name := range []string{"handler.go.txt", "handler_test.go.txt", "migrations/001.sql", "[docsDir]/README.md"} {
+		must(t, os.WriteFile(filepath.Join(tplDir, name), []byte(name), 0644))
+	}
+
+	// Create generator instance
+	cfg := config.Generator{
+		Name: "test-gen",
+		Args: []config.Arg{
+			{Name: "withTests", Type: config.ArgBool, Default: "false"},
+			{Name: "db"},
+			{Name: "docsDir", Required: new(bool)},
+		},
+		When: map[string]string{
+			"*_test.go.txt": ".withTests",
+			"migrations/":   `eq .db "postgres"`,
+		},
+	}
+	g := New(cfg, "test-gen", rootDir)
+
+	tests := []struct {
+		name   string
+		config map[string]any
+		want   []string
+	}{
+		{
+			name:   "all included",
+			config: map[string]any{"withTests": "true", "db": "postgres", "docsDir": "docs"},
+			want:   []string{"docs/README.md", "handler.go.txt", "handler_test.go.txt", "migrations/001.sql"},
+		},
+		{
+			name:   "all optional files skipped",
+			config: map[string]any{"db": "sqlite"},
+			want:   []string{"handler.go.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outDir := filepath.Join(outDir, tt.name)
+			if _, err := g.Run([]Generator{g}, tt.config, outDir); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			var got []string
+			must(t, filepath.WalkDir(outDir, func(p string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					rel, _ := filepath.Rel(outDir, p)
+					got = append(got, rel)
+				}
+				return err
+			}))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Run() rendered %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerator_RunWithTemplateEngines(t *testing.T) {
+	tests := []struct {
+		name       string
+		engine     string
+		delimiters []string
+		template   string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "custom delimiters",
+			delimiters: []string{"[[", "]]"},
+			template:   "---\nto: [[ .name ]].yaml\n---\nname: [[ .name | kebab ]]\nimage: {{ .Values.image }}\n",
+			want:       "name: my-chart\nimage: {{ .Values.image }}\n",
+		},
+		{
+			name:     "js engine",
+			engine:   config.EngineJS,
+			template: "---\nto: ${name}.yaml\n---\nname: ${convertCase(\"kebab\", name)}\nimage: {{ .Values.image }}\n",
+			want:     "name: my-chart\nimage: {{ .Values.image }}\n",
+		},
+		{
+			name:     "unknown engine",
+			engine:   "jinja",
+			template: "x",
+			wantErr:  true,
+		},
+		{
+			name:       "one delimiter",
+			delimiters: []string{"[["},
+			template:   "x",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			rootDir := filepath.Join(tmpDir, "root")
+			outDir := filepath.Join(tmpDir, "out")
+
+			must(t, os.MkdirAll(filepath.Join(rootDir, ".g", "test-gen", "tpl"), 0755))
+			must(t, os.WriteFile(filepath.Join(rootDir, ".g", "test-gen", "tpl", "chart.yaml.tpl"), []byte(tt.template), 0644))
+
+			cfg := config.Generator{
+				Name:       "test-gen",
+				Args:       []config.Arg{{Name: "name"}},
+				Engine:     tt.engine,
+				Delimiters: tt.delimiters,
+			}
+			g := New(cfg, "test-gen", rootDir)
+
+			_, err := g.Run([]Generator{g}, map[string]any{"name": "MyChart"}, outDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			content, err := os.ReadFile(filepath.Join(outDir, "MyChart.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("Run() output = %q, want %q", content, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerator_RunWithPartials(t *testing.T) {
+	// Create temporary test directory
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	libDir := filepath.Join(tmpDir, "lib")
+	outDir := filepath.Join(tmpDir, "out")
+
+	// Create shared partials locally and in an included config
+	must(t, os.MkdirAll(filepath.Join(rootDir, ".g", "test-gen", "tpl"), 0755))
+	must(t, os.MkdirAll(filepath.Join(rootDir, ".g", PartialsDir, "go"), 0755))
+	must(t, os.MkdirAll(filepath.Join(libDir, ".g", PartialsDir), 0755))
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", PartialsDir, "go", "header.tpl"), []byte("// Code for {{ .name }}.\n"), 0644))
+	must(t, os.WriteFile(filepath.Join(libDir, ".g", PartialsDir, "license.tpl"), []byte("// Copyright ACME"), 0644))
+
+	tplContent := "{{ template \"lib:license\" }}\n{{ template \"go/header\" . }}package {{ .name }}\n"
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "test-gen", "tpl", "doc.txt.tpl"), []byte(tplContent), 0644))
+
+	// Create generator instances
+	g := New(config.Generator{Name: "test-gen", Args: []config.Arg{{Name: "name"}}}, "test-gen", rootDir)
+	lib := New(config.Generator{Name: "other"}, "lib:other", libDir)
+	lib.Namespace = "lib"
+
+	// Run generator
+	generators := []Generator{g, lib}
+	if _, err := g.Run(generators, map[string]any{"name": "web"}, outDir); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Verify output
+	content, err := os.ReadFile(filepath.Join(outDir, "doc.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "// Copyright ACME\n// Code for web.\npackage web\n"
+	if string(content) != expected {
+		t.Errorf("Run() output = %q, want %q", string(content), expected)
+	}
+}
+
+func TestGenerator_RunExtends(t *testing.T) {
+	// Create temporary test directory
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	libDir := filepath.Join(tmpDir, "lib")
+	outDir := filepath.Join(tmpDir, "out")
+
+	// Create a base generator in an included config
+	must(t, os.MkdirAll(filepath.Join(libDir, ".g", "handler", "tpl"), 0755))
+	must(t, os.MkdirAll(filepath.Join(libDir, ".g", PartialsDir), 0755))
+	must(t, os.WriteFile(filepath.Join(libDir, ".g", PartialsDir, "header.tpl"), []byte("// {{ .title }}"), 0644))
+	must(t, os.WriteFile(filepath.Join(libDir, ".g", "handler", "config.js"), []byte("const mark = \"\";\nfunction config(input) { return { title: input.name.toUpperCase() + mark }; }"), 0644))
+	must(t, os.WriteFile(filepath.Join(libDir, ".g", "handler", "tpl", "handler.go.tpl"), []byte("{{ template \"header\" . }}\npackage {{ .name }}\n"), 0644))
+	must(t, os.WriteFile(filepath.Join(libDir, ".g", "handler", "tpl", "README.md.tpl"), []byte("# {{ .title }}\n"), 0644))
+
+	// Override one template and config.js locally, and add a template. The
+	// local config.js starts as a copy of the base's, declaring the same const.
+	must(t, os.MkdirAll(filepath.Join(rootDir, ".g", "handler", "tpl"), 0755))
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "handler", "config.js"), []byte("const mark = \"!\";\nfunction config(input) { return { title: input.title + mark }; }"), 0644))
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "handler", "tpl", "README.md.tpl"), []byte("# {{ .title }} (local)\n"), 0644))
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "handler", "tpl", "handler_test.go.tpl"), []byte("package {{ .name }}\n"), 0644))
+
+	// Create generator instances
+	lib := New(config.Generator{
+		Name:           "handler",
+		Args:           []config.Arg{{Name: "name"}},
+		DataTransforms: []config.DataTransform{{File: "app.yaml", Append: "handlers", Value: "{{ .name }}"}},
+	}, "lib:handler", libDir)
+	lib.Namespace = "lib"
+	g := New(config.Generator{Name: "handler", Extends: "lib:handler"}, "handler", rootDir)
+
+	generators := []Generator{g, lib}
+	must(t, ResolveExtends(generators))
+	g = generators[0]
+
+	templates, err := g.Templates()
+	must(t, err)
+	expected := []string{"README.md.tpl", "handler.go.tpl", "handler_test.go.tpl"}
+	if !reflect.DeepEqual(templates, expected) {
+		t.Errorf("Templates() = %v, want %v", templates, expected)
+	}
+
+	// Run generator
+	must(t, os.MkdirAll(outDir, 0755))
+	must(t, os.WriteFile(filepath.Join(outDir, "app.yaml"), []byte("handlers: []\n"), 0644))
+	if _, err := g.Run(generators, map[string]any{"name": "web"}, outDir); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Verify output
+	for file, expected := range map[string]string{
+		"handler.go":      "// WEB!\npackage web\n",
+		"README.md":       "# WEB! (local)\n",
+		"handler_test.go": "package web\n",
+		"app.yaml":        "handlers: [web]\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(outDir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expected {
+			t.Errorf("Run() %s = %q, want %q", file, string(content), expected)
+		}
+	}
+}
+
+func TestGenerator_RunComposite(t *testing.T) {
+	// Create temporary test directory
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	outDir := filepath.Join(tmpDir, "out")
+
+	// Create templates for the used generators and the composite itself
+	for name, files := range map[string]map[string]string{
+		"route":  {"routes/[path].txt.tpl": "{{ .method }} /{{ .path }}\n"},
+		"view":   {"views/[name].txt.tpl": "view {{ .name }}\n"},
+		"action": {"actions.txt.tpl": "{{ .funcName }}: {{ .method }} /{{ .path }} ({{ .viewFile }})\n"},
+	} {
+		for file, content := range files {
+			must(t, os.MkdirAll(filepath.Join(rootDir, ".g", name, "tpl", filepath.Dir(file)), 0755))
+			must(t, os.WriteFile(filepath.Join(rootDir, ".g", name, "tpl", file), []byte(content), 0644))
+		}
+	}
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "view", "config.js"), []byte(`function config(input) { return { viewFile: "views/" + input.name + ".txt" }; }`), 0644))
+
+	// Create generator instances
+	generators := []Generator{
+		New(config.Generator{
+			Name: "action",
+			Args: []config.Arg{{Name: "funcName"}},
+			Use: []config.Use{
+				{Generator: "route"},
+				{Generator: "view", With: map[string]string{"name": "{{ .funcName | snake }}"}},
+			},
+			Post: []string{"touch {{ .funcName }}.flag"},
+		}, "action", rootDir),
+		New(config.Generator{Name: "route", Args: []config.Arg{{Name: "method"}, {Name: "path"}}}, "route", rootDir),
+		New(config.Generator{Name: "view", Args: []config.Arg{{Name: "name"}}}, "view", rootDir),
+	}
+	must(t, ResolveUse(generators))
+
+	// Run generator
+	g := generators[0]
+	gConfig, err := g.Run(generators, map[string]any{"funcName": "ListPosts", "method": "get", "path": "posts"}, outDir)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if _, ok := gConfig["name"]; ok {
+		t.Errorf("Run() config = %v, mapped argument leaked into the composite", gConfig)
+	}
+
+	// Verify output
+	for file, expected := range map[string]string{
+		"routes/posts.txt":     "get /posts\n",
+		"views/list_posts.txt": "view list_posts\n",
+		"actions.txt":          "ListPosts: get /posts (views/list_posts.txt)\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(outDir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expected {
+			t.Errorf("Run() %s = %q, want %q", file, string(content), expected)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(outDir, "ListPosts.flag")); err != nil {
+		t.Error("Post command of the composite did not run")
+	}
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"go.quinn.io/g/config"
+	"go.quinn.io/g/fileops"
+	"go.quinn.io/g/goedit"
+	tpl "go.quinn.io/g/template"
+)
+
+// applyGoTransform renders the transform's code and applies the edit to its
+// file. Unlike JavaScript transforms, a missing file or target is an error.
+func applyGoTransform(processor *tpl.Processor, t config.GoTransform, outDir string, gConfig map[string]any) error {
+	file, err := processor.RenderPath("file", t.File, gConfig)
+	if err != nil {
+		return fmt.Errorf("[GO TRANSFORM:%s] %w", t.File, err)
+	}
+
+	sourcePath := path.Join(outDir, file)
+	sourceData, err := fileops.ReadFile(sourcePath)
+	if err != nil {
+		return fmt.Errorf("[GO TRANSFORM:%s] %w", file, err)
+	}
+
+	code, err := processor.Render("code", t.Code, gConfig)
+	if err != nil {
+		return fmt.Errorf("[GO TRANSFORM:%s] %w", file, err)
+	}
+
+	var actions []string
+	for action, target := range map[string]string{
+		"addImport":    t.AddImport,
+		"addMethod":    t.AddMethod,
+		"appendToFunc": t.AppendToFunc,
+		"addField":     t.AddField,
+		"addCase":      t.AddCase,
+	} {
+		if target != "" {
+			actions = append(actions, action)
+		}
+	}
+	if len(actions) != 1 {
+		sort.Strings(actions)
+		return fmt.Errorf("[GO TRANSFORM:%s] expected exactly one action, got %v", file, actions)
+	}
+
+	var result string
+	switch {
+	case t.AddImport != "":
+		var spec string
+		if spec, err = processor.Render("addImport", t.AddImport, gConfig); err == nil {
+			result, err = goedit.AddImport(sourceData, spec)
+		}
+	case t.AddMethod != "":
+		result, err = goedit.AddMethod(sourceData, t.AddMethod, code)
+	case t.AppendToFunc != "":
+		result, err = goedit.AppendToFunc(sourceData, t.AppendToFunc, code)
+	case t.AddField != "":
+		result, err = goedit.AddField(sourceData, t.AddField, code)
+	case t.AddCase != "":
+		result, err = goedit.AddCase(sourceData, t.AddCase, t.Switch, code)
+	}
+	if err != nil {
+		return fmt.Errorf("[GO TRANSFORM:%s] %s: %w", file, actions[0], err)
+	}
+
+	if result == sourceData {
+		return nil
+	}
+
+	if err := fileops.WriteFile(sourcePath, result); err != nil {
+		return err
+	}
+
+	return fileops.GoFmt(sourcePath)
+}
@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Location returns where the generator is defined, such as "g.yaml:12", or
+// its command when that is unknown
+func (g *Generator) Location() string {
+	switch {
+	case g.File == "":
+		return g.Cmd
+	case g.Line == 0:
+		return g.File
+	default:
+		return fmt.Sprintf("%s:%d", g.File, g.Line)
+	}
+}
+
+// Validate checks the references between generators: every generator named
+// by extends or use must exist, and no generator may depend on itself
+// through them. All problems are reported, each at the location of the
+// generator it was found in.
+func Validate(generators []Generator) error {
+	var errs []error
+
+	index := map[string]int{}
+	for i, g := range generators {
+		if j, ok := index[g.Cmd]; ok {
+			errs = append(errs, fmt.Errorf("%s: duplicate generator %s, also defined at %s", g.Location(), g.Cmd, generators[j].Location()))
+			continue
+		}
+		index[g.Cmd] = i
+	}
+
+	deps := make([][]int, len(generators))
+	for i, g := range generators {
+		if g.Cfg.Extends != "" {
+			if j, ok := index[g.Cfg.Extends]; ok {
+				deps[i] = append(deps[i], j)
+			} else {
+				errs = append(errs, fmt.Errorf("%s: %s extends unknown generator %s", g.Location(), g.Cmd, g.Cfg.Extends))
+			}
+		}
+		for _, use := range g.Cfg.Use {
+			if j, ok := index[use.Generator]; ok {
+				deps[i] = append(deps[i], j)
+			} else {
+				errs = append(errs, fmt.Errorf("%s: %s uses unknown generator %s", g.Location(), g.Cmd, use.Generator))
+			}
+		}
+	}
+
+	// Walk the graph depth first, reporting a cycle whenever a dependency
+	// leads back to a generator still being visited
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(generators))
+	var stack []int
+
+	var visit func(i int)
+	visit = func(i int) {
+		state[i] = visiting
+		stack = append(stack, i)
+
+		for _, j := range deps[i] {
+			switch state[j] {
+			case unvisited:
+				visit(j)
+			case visiting:
+				var cycle []string
+				for _, k := range stack[slices.Index(stack, j):] {
+					cycle = append(cycle, generators[k].Cmd)
+				}
+				cycle = append(cycle, generators[j].Cmd)
+				errs = append(errs, fmt.Errorf("%s: cycle: %s", generators[j].Location(), strings.Join(cycle, " -> ")))
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[i] = visited
+	}
+
+	for i := range generators {
+		if state[i] == unvisited {
+			visit(i)
+		}
+	}
+
+	return errors.Join(errs...)
+}
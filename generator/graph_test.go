@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"go.quinn.io/g/config"
+)
+
+func TestValidate(t *testing.T) {
+	gen := func(cmd string, line int, extends string, use ...string) Generator {
+		cfg := config.Generator{Name: cmd, Extends: extends}
+		for _, u := range use {
+			cfg.Use = append(cfg.Use, config.Use{Generator: u})
+		}
+		g := New(cfg, cmd, "")
+		g.File = "g.yaml"
+		g.Line = line
+		return g
+	}
+
+	tests := []struct {
+		name       string
+		generators []Generator
+		wantErrs   []string
+	}{
+		{
+			name: "valid in any order",
+			generators: []Generator{
+				gen("action", 1, "", "route", "view"),
+				gen("view", 2, "lib:view"),
+				gen("route", 3, ""),
+				gen("lib:view", 4, ""),
+			},
+		},
+		{
+			name: "missing references",
+			generators: []Generator{
+				gen("action", 1, "", "route", "view"),
+				gen("handler", 5, "lib:handler"),
+				gen("route", 9, ""),
+			},
+			wantErrs: []string{
+				"g.yaml:1: action uses unknown generator view",
+				"g.yaml:5: handler extends unknown generator lib:handler",
+			},
+		},
+		{
+			name: "cycles",
+			generators: []Generator{
+				gen("a", 1, "", "b"),
+				gen("b", 2, "", "c"),
+				gen("c", 3, "", "a"),
+				gen("self", 4, "", "self"),
+				gen("child", 5, "parent"),
+				gen("parent", 6, "", "child"),
+			},
+			wantErrs: []string{
+				"g.yaml:1: cycle: a -> b -> c -> a",
+				"g.yaml:4: cycle: self -> self",
+				"g.yaml:5: cycle: child -> parent -> child",
+			},
+		},
+		{
+			name: "duplicates",
+			generators: []Generator{
+				gen("route", 1, ""),
+				gen("route", 7, ""),
+			},
+			wantErrs: []string{
+				"g.yaml:7: duplicate generator route, also defined at g.yaml:1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.generators)
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() expected errors %v", tt.wantErrs)
+			}
+
+			got := strings.Split(err.Error(), "\n")
+			if strings.Join(got, "\n") != strings.Join(tt.wantErrs, "\n") {
+				t.Errorf("Validate() errors =\n%s\nwant\n%s", err, strings.Join(tt.wantErrs, "\n"))
+			}
+		})
+	}
+}
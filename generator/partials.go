@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.quinn.io/g/fileops"
+)
+
+// PartialsDir is the directory in .g holding templates shared by every
+// generator, rather than a generator of its own
+const PartialsDir = "_partials"
+
+// partials loads the shared templates available to the generator: those
+// of its own .g directory by name, and those of included configs prefixed
+// with their namespace, such as "lib:license"
+func (g *Generator) partials(generators []Generator) (map[string]string, error) {
+	// The partials of the generators this one extends are available by name
+	// too, for their templates to use
+	partials := map[string]string{}
+	for _, gen := range g.chain() {
+		if err := loadPartials(partials, gen.rootDir, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := map[string]bool{g.rootDir: true}
+	for _, other := range generators {
+		if other.Namespace == "" || other.Namespace == g.Namespace || seen[other.rootDir] {
+			continue
+		}
+		seen[other.rootDir] = true
+
+		if err := loadPartials(partials, other.rootDir, other.Namespace+":"); err != nil {
+			return nil, err
+		}
+	}
+
+	return partials, nil
+}
+
+// loadPartials adds the partials in rootDir's .g/_partials directory. A
+// partial is named after its path in the directory, without .tpl.
+func loadPartials(partials map[string]string, rootDir, prefix string) error {
+	dir := filepath.Join(rootDir, ".g", PartialsDir)
+
+	err := filepath.WalkDir(dir, func(sourcePath string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		data, err := fileops.ReadFile(sourcePath)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.ToSlash(strings.TrimPrefix(sourcePath, dir+string(filepath.Separator)))
+		partials[prefix+strings.TrimSuffix(name, ".tpl")] = data
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error loading partials: %w", err)
+	}
+
+	return nil
+}
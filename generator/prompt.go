@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.quinn.io/g/config"
+	"go.quinn.io/g/fileops"
+)
+
+// PromptArg asks the user for an argument value, showing its description,
+// choices and default. Invalid values are reported and asked for again.
+// Variadic arguments take a space-separated list.
+func PromptArg(in *bufio.Reader, arg config.Arg) (any, error) {
+	var label strings.Builder
+	label.WriteString(arg.Name)
+	if arg.Description != "" {
+		fmt.Fprintf(&label, " - %s", arg.Description)
+	}
+	if len(arg.Enum) > 0 {
+		fmt.Fprintf(&label, " [%s]", strings.Join(arg.Enum, "/"))
+	}
+	if arg.Default != "" {
+		fmt.Fprintf(&label, " (default: %s)", arg.Default)
+	}
+	label.WriteString(": ")
+
+	for {
+		fileops.Print("%s", label.String())
+
+		line, err := in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, fmt.Errorf("error reading argument %s: %w", arg.Name, err)
+		}
+
+		var input any = strings.TrimSpace(line)
+		if arg.Variadic {
+			input = strings.Fields(line)
+		}
+
+		value, resolveErr := arg.Resolve(input)
+		if resolveErr == nil {
+			return value, nil
+		}
+		if err == io.EOF {
+			return nil, resolveErr
+		}
+		fileops.Print("%v\n", resolveErr)
+	}
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.quinn.io/g/config"
+)
+
+func TestPromptArg(t *testing.T) {
+	arg := config.Arg{Name: "method", Type: config.ArgEnum, Enum: []string{"get", "post"}}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    any
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			input: "get\n",
+			want:  "get",
+		},
+		{
+			name:  "asks again after invalid value",
+			input: "gett\n\npost\n",
+			want:  "post",
+		},
+		{
+			name:  "last line without newline",
+			input: "post",
+			want:  "post",
+		},
+		{
+			name:    "no input",
+			input:   "",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "invalid value at end of input",
+			input:   "gett",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PromptArg(bufio.NewReader(strings.NewReader(tt.input)), arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PromptArg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PromptArg() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	got, err := PromptArg(bufio.NewReader(strings.NewReader("\n")), config.Arg{Name: "pkg", Default: "routes"})
+	if err != nil || got != "routes" {
+		t.Errorf("PromptArg() with default = %v, %v, want routes", got, err)
+	}
+}
+
+func TestPromptArg_Variadic(t *testing.T) {
+	arg := config.Arg{Name: "sizes", Type: config.ArgInt, Variadic: true}
+
+	got, err := PromptArg(bufio.NewReader(strings.NewReader("1 x\n1  2\n")), arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []any{1, 2}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("PromptArg() = %v, want %v", got, expected)
+	}
+}
@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"go.quinn.io/g/config"
+	"go.quinn.io/g/fileops"
+	"go.quinn.io/g/jsvm"
+	tpl "go.quinn.io/g/template"
+)
+
+// applyTransform runs the transform's JavaScript function on every file
+// matching its pattern. What happens when nothing matches is decided by the
+// transform's missing policy, which skips by default.
+func applyTransform(vm *jsvm.VM, processor *tpl.Processor, t config.Transform, outDir string, gConfig map[string]any) error {
+	pattern, err := processor.RenderPath("transform file", t.File, gConfig)
+	if err != nil {
+		return fmt.Errorf("[TRANSFORM:%s] %w", t.Function, err)
+	}
+
+	matches, err := fileops.Glob(path.Join(outDir, pattern))
+	if err != nil {
+		return fmt.Errorf("[TRANSFORM:%s] %w", t.Function, err)
+	}
+
+	if len(matches) == 0 {
+		switch t.Missing {
+		case config.MissingError:
+			return fmt.Errorf("[TRANSFORM:%s] no files match %s", t.Function, pattern)
+		case config.MissingSeed:
+			target, err := seed(processor, t, pattern, outDir, gConfig)
+			if err != nil {
+				return fmt.Errorf("[TRANSFORM:%s] %w", t.Function, err)
+			}
+			matches = []string{target}
+		default:
+			fileops.Print("No files match %s. Will not perform transformation %s.\n", pattern, t.Function)
+			return nil
+		}
+	}
+
+	for _, sourcePath := range matches {
+		sourceData, err := fileops.ReadFile(sourcePath)
+		if err != nil {
+			return fmt.Errorf("[TRANSFORM:%s] %w", t.Function, err)
+		}
+
+		result, err := vm.RunTransform(t.Function, sourceData, gConfig)
+		if err != nil {
+			return err
+		}
+
+		if result == sourceData {
+			continue
+		}
+
+		if err := fileops.WriteFile(sourcePath, result); err != nil {
+			return err
+		}
+
+		if err := fileops.GoFmt(sourcePath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seed creates a missing transform target from the transform's seed template
+func seed(processor *tpl.Processor, t config.Transform, pattern, outDir string, gConfig map[string]any) (string, error) {
+	if strings.ContainsAny(pattern, `*?[\`) {
+		return "", fmt.Errorf("cannot seed glob pattern %s", pattern)
+	}
+
+	data, err := processor.Render("seed", t.Seed, gConfig)
+	if err != nil {
+		return "", err
+	}
+
+	target := filepath.Join(outDir, pattern)
+	if err := fileops.MkdirP(target); err != nil {
+		return "", err
+	}
+	if err := fileops.WriteFile(target, data); err != nil {
+		return "", err
+	}
+
+	return target, nil
+}
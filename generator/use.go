@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.quinn.io/g/config"
+	tpl "go.quinn.io/g/template"
+)
+
+// ResolveUse adds to the args of every composite generator the args of the
+// generators it uses, except those it sets with `with`, so they can be
+// given on the command line. Args the composite declares itself come first.
+func ResolveUse(generators []Generator) error {
+	resolved := map[string]bool{}
+
+	var resolve func(i int, chain []string) error
+	resolve = func(i int, chain []string) error {
+		g := &generators[i]
+		if len(g.Cfg.Use) == 0 || resolved[g.Cmd] {
+			return nil
+		}
+		if slices.Contains(chain, g.Cmd) {
+			return fmt.Errorf("use cycle: %s", strings.Join(append(chain, g.Cmd), " -> "))
+		}
+
+		args := slices.Clone(g.Cfg.Args)
+		for _, use := range g.Cfg.Use {
+			j := slices.IndexFunc(generators, func(other Generator) bool {
+				return other.Cmd == use.Generator
+			})
+			if j == -1 {
+				return fmt.Errorf("%s uses unknown generator %s", g.Cmd, use.Generator)
+			}
+			if err := resolve(j, append(chain, g.Cmd)); err != nil {
+				return err
+			}
+
+			for _, arg := range generators[j].Cfg.Args {
+				_, mapped := use.With[arg.Name]
+				if !mapped && !slices.ContainsFunc(args, func(a config.Arg) bool { return a.Name == arg.Name }) {
+					args = append(args, arg)
+				}
+			}
+		}
+
+		g.Cfg.Args = args
+		resolved[g.Cmd] = true
+		return nil
+	}
+
+	var errs []error
+	for i := range generators {
+		if err := resolve(i, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runUse runs a generator used by g with a copy of g's config, in which the
+// args mapped by `with` are set. Values the generator adds to the config,
+// other than the mapped args, are merged back without replacing g's own.
+func (g *Generator) runUse(generators []Generator, processor *tpl.Processor, use config.Use, gConfig map[string]any, outDir string) error {
+	gen, err := Find(generators, use.Generator)
+	if err != nil {
+		return fmt.Errorf("[USE:%s] error finding generator: %w", use.Generator, err)
+	}
+	gen.Conflict = g.Conflict
+
+	// Guard against generators that use themselves, which Validate reports
+	// when they are loaded
+	gen.callers = append(slices.Clone(g.callers), g.Cmd)
+	if slices.Contains(gen.callers, gen.Cmd) {
+		return fmt.Errorf("[USE:%s] cycle: %s", use.Generator, strings.Join(append(gen.callers, gen.Cmd), " -> "))
+	}
+
+	useConfig := maps.Clone(gConfig)
+	for name, text := range use.With {
+		value, err := withValue(processor, name, text, gConfig)
+		if err != nil {
+			return fmt.Errorf("[USE:%s] %w", use.Generator, err)
+		}
+		useConfig[name] = value
+	}
+
+	result, err := gen.run(generators, useConfig, outDir)
+	if err != nil {
+		return fmt.Errorf("[USE:%s] error running generator : %w", use.Generator, err)
+	}
+
+	for k, v := range result {
+		_, mapped := use.With[k]
+		if _, ok := gConfig[k]; !ok && !mapped {
+			gConfig[k] = v
+		}
+	}
+	return nil
+}
+
+var configRef = regexp.MustCompile(`^\{\{-?\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*-?\}\}$`)
+
+// withValue renders a value of `with`. A template that is a single reference
+// such as "{{ .fields }}" passes the config value on as is, so lists,
+// numbers and booleans keep their type rather than becoming strings.
+func withValue(processor *tpl.Processor, name, text string, gConfig map[string]any) (any, error) {
+	if m := configRef.FindStringSubmatch(strings.TrimSpace(text)); m != nil {
+		if value, ok := gConfig[m[1]]; ok {
+			return value, nil
+		}
+	}
+	return processor.Render("with "+name, text, gConfig)
+}
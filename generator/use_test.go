@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.quinn.io/g/config"
+)
+
+func TestResolveUse(t *testing.T) {
+	gen := func(cmd string, use []config.Use, args ...string) Generator {
+		cfg := config.Generator{Name: cmd, Use: use}
+		for _, arg := range args {
+			cfg.Args = append(cfg.Args, config.Arg{Name: arg})
+		}
+		return New(cfg, cmd, "")
+	}
+
+	tests := []struct {
+		name       string
+		generators []Generator
+		wantArgs   map[string][]string
+		wantErr    string
+	}{
+		{
+			name: "union of args",
+			generators: []Generator{
+				gen("action", []config.Use{{Generator: "route"}, {Generator: "view"}}),
+				gen("route", nil, "method", "path"),
+				gen("view", nil, "name", "path"),
+			},
+			wantArgs: map[string][]string{
+				"action": {"method", "path", "name"},
+				"route":  {"method", "path"},
+				"view":   {"name", "path"},
+			},
+		},
+		{
+			name: "mapped args and own args",
+			generators: []Generator{
+				gen("action", []config.Use{
+					{Generator: "route"},
+					{Generator: "view", With: map[string]string{"name": "{{ .funcName }}"}},
+				}, "funcName"),
+				gen("route", nil, "method"),
+				gen("view", nil, "name"),
+			},
+			wantArgs: map[string][]string{
+				"action": {"funcName", "method"},
+				"route":  {"method"},
+				"view":   {"name"},
+			},
+		},
+		{
+			name: "nested composites",
+			generators: []Generator{
+				gen("feature", []config.Use{{Generator: "action"}, {Generator: "model"}}),
+				gen("action", []config.Use{{Generator: "route"}}),
+				gen("route", nil, "path"),
+				gen("model", nil, "table"),
+			},
+			wantArgs: map[string][]string{
+				"feature": {"path", "table"},
+				"action":  {"path"},
+				"route":   {"path"},
+				"model":   {"table"},
+			},
+		},
+		{
+			name: "unknown generator",
+			generators: []Generator{
+				gen("action", []config.Use{{Generator: "missing"}}),
+			},
+			wantErr: "action uses unknown generator missing",
+		},
+		{
+			name: "cycle",
+			generators: []Generator{
+				gen("a", []config.Use{{Generator: "b"}}),
+				gen("b", []config.Use{{Generator: "a"}}),
+			},
+			wantErr: "use cycle: a -> b -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ResolveUse(tt.generators)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveUse() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveUse() error = %v", err)
+			}
+
+			for _, g := range tt.generators {
+				var args []string
+				for _, arg := range g.Cfg.Args {
+					args = append(args, arg.Name)
+				}
+				if !reflect.DeepEqual(args, tt.wantArgs[g.Cmd]) {
+					t.Errorf("%s args = %v, want %v", g.Cmd, args, tt.wantArgs[g.Cmd])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerator_RunRecursiveUse(t *testing.T) {
+	// Unvalidated generators that use each other fail instead of recursing
+	generators := []Generator{
+		New(config.Generator{Name: "a", Use: []config.Use{{Generator: "b"}}}, "a", t.TempDir()),
+		New(config.Generator{Name: "b", Use: []config.Use{{Generator: "a"}}}, "b", t.TempDir()),
+	}
+
+	_, err := generators[0].Run(generators, map[string]any{}, t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "cycle: a -> b -> a") {
+		t.Errorf("Run() error = %v, want a cycle error", err)
+	}
+}
+
+func TestGenerator_RunUseTypedWith(t *testing.T) {
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	outDir := filepath.Join(tmpDir, "out")
+
+	must(t, os.MkdirAll(filepath.Join(rootDir, ".g", "model", "tpl"), 0755))
+	must(t, os.WriteFile(filepath.Join(rootDir, ".g", "model", "tpl", "model.txt.tpl"), []byte("{{ range .columns }}[{{ . }}]{{ end }} {{ .table }}\n"), 0644))
+
+	// A single reference keeps the list, other templates render to strings
+	generators := []Generator{
+		New(config.Generator{
+			Name: "resource",
+			Args: []config.Arg{{Name: "name"}, {Name: "fields", Variadic: true}},
+			Use: []config.Use{{Generator: "model", With: map[string]string{
+				"columns": "{{ .fields }}",
+				"table":   "{{ .name }}s",
+			}}},
+		}, "resource", rootDir),
+		New(config.Generator{Name: "model", Args: []config.Arg{{Name: "columns", Variadic: true}, {Name: "table"}}}, "model", rootDir),
+	}
+	must(t, ResolveUse(generators))
+
+	if _, err := generators[0].Run(generators, map[string]any{"name": "post", "fields": []any{"title", "body"}}, outDir); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outDir, "model.txt"))
+	must(t, err)
+	if want := "[title][body] posts\n"; string(content) != want {
+		t.Errorf("Run() model.txt = %q, want %q", content, want)
+	}
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tpl "go.quinn.io/g/template"
+)
+
+// included reports whether every when rule matching the template path holds
+func (g *Generator) included(processor *tpl.Processor, templatePath string, gConfig map[string]any) (bool, error) {
+	rules := make([]string, 0, len(g.Cfg.When))
+	for rule := range g.Cfg.When {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	for _, rule := range rules {
+		if !matchRule(rule, templatePath) {
+			continue
+		}
+
+		cond := g.Cfg.When[rule]
+		result, err := processor.Render("when "+rule, "{{ if "+cond+" }}true{{ end }}", gConfig)
+		if err != nil {
+			return false, err
+		}
+		if result != "true" {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// matchRule reports whether a when rule applies to a template path. A rule
+// matches the path itself, anything below it, or paths matching it as a
+// glob pattern.
+func matchRule(rule, templatePath string) bool {
+	rule = strings.TrimSuffix(rule, "/")
+	if templatePath == rule || strings.HasPrefix(templatePath, rule+"/") {
+		return true
+	}
+	ok, _ := filepath.Match(rule, templatePath)
+	return ok
+}
@@ -5,11 +5,14 @@ go 1.23.0
 toolchain go1.23.2
 
 require (
+	github.com/aymanbagabas/go-udiff v0.2.0
 	github.com/dop251/goja v0.0.0-20240707163329-b1681fb2a2f5
 	github.com/go-git/go-git/v5 v5.12.0
 	github.com/hay-kot/scaffold v0.5.0
 	github.com/rs/zerolog v1.33.0
+	golang.org/x/term v0.25.0
 	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1
 	mvdan.cc/sh/v3 v3.10.0
 )
 
@@ -39,7 +42,6 @@ require (
 	golang.org/x/net v0.27.0 // indirect
 	golang.org/x/sync v0.8.0 // indirect
 	golang.org/x/sys v0.26.0 // indirect
-	golang.org/x/term v0.25.0 // indirect
 	golang.org/x/text v0.18.0 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
 )
@@ -0,0 +1,217 @@
+package goedit
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// Node describes a declaration or call expression found in a file. Start
+// and End are byte offsets into the source the node was found in.
+type Node struct {
+	Kind  string
+	Name  string
+	Recv  string
+	Args  []string
+	Start int
+	End   int
+	Text  string
+}
+
+// File is Go source that can be queried and edited. Every edit reformats
+// the source, so nodes found before an edit must be looked up again; edits
+// given a node that no longer matches the source fail.
+type File struct {
+	src string
+}
+
+// Parse parses and formats Go source for querying and editing
+func Parse(src string) (*File, error) {
+	if _, _, err := parse(src); err != nil {
+		return nil, err
+	}
+
+	formatted, err := formatSource(src)
+	if err != nil {
+		return nil, err
+	}
+	return &File{src: formatted}, nil
+}
+
+// Source returns the current, formatted source
+func (f *File) Source() string {
+	return f.src
+}
+
+// Funcs returns the function and method declarations in the file
+func (f *File) Funcs() []Node {
+	fset, file, _ := parse(f.src)
+
+	var nodes []Node
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		node := f.node(fset, fn, "func", fn.Name.Name)
+		if fn.Recv != nil {
+			node.Kind = "method"
+			node.Recv = receiverName(fn)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// Types returns the type declarations in the file. Kind is "struct",
+// "interface" or "type".
+func (f *File) Types() []Node {
+	fset, file, _ := parse(f.src)
+
+	var nodes []Node
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+
+			kind := "type"
+			switch ts.Type.(type) {
+			case *ast.StructType:
+				kind = "struct"
+			case *ast.InterfaceType:
+				kind = "interface"
+			}
+
+			// Ungrouped declarations include the type keyword
+			var n ast.Node = ts
+			if !gen.Lparen.IsValid() {
+				n = gen
+			}
+			nodes = append(nodes, f.node(fset, n, kind, ts.Name.Name))
+		}
+	}
+	return nodes
+}
+
+// Calls returns the call expressions whose function matches name, such as
+// "e.GET". An empty name returns every call.
+func (f *File) Calls(name string) []Node {
+	fset, file, _ := parse(f.src)
+
+	var nodes []Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		fun := nodeString(fset, call.Fun)
+		if name != "" && fun != name {
+			return true
+		}
+
+		node := f.node(fset, call, "call", fun)
+		node.Args = []string{}
+		for _, arg := range call.Args {
+			node.Args = append(node.Args, f.src[offset(fset, arg.Pos()):offset(fset, arg.End())])
+		}
+		nodes = append(nodes, node)
+		return true
+	})
+	return nodes
+}
+
+// Replace replaces the source of a node with code
+func (f *File) Replace(node Node, code string) error {
+	return f.edit(node, node.Start, node.End, code)
+}
+
+// InsertBefore inserts code on the lines before a node
+func (f *File) InsertBefore(node Node, code string) error {
+	return f.edit(node, node.Start, node.Start, code+"\n")
+}
+
+// InsertAfter inserts code on the lines after a node, keeping a trailing
+// comment with the node
+func (f *File) InsertAfter(node Node, code string) error {
+	if err := f.check(node); err != nil {
+		return err
+	}
+	at := lineEnd(f.src, node.End)
+	return f.edit(node, at, at, "\n"+code)
+}
+
+// Remove removes a node from the source
+func (f *File) Remove(node Node) error {
+	return f.edit(node, node.Start, node.End, "")
+}
+
+// AddImport applies AddImport to the file
+func (f *File) AddImport(spec string) error {
+	return f.apply(AddImport(f.src, spec))
+}
+
+// AddMethod applies AddMethod to the file
+func (f *File) AddMethod(typeName, code string) error {
+	return f.apply(AddMethod(f.src, typeName, code))
+}
+
+// AppendToFunc applies AppendToFunc to the file
+func (f *File) AppendToFunc(funcName, code string) error {
+	return f.apply(AppendToFunc(f.src, funcName, code))
+}
+
+// AddField applies AddField to the file
+func (f *File) AddField(structName, code string) error {
+	return f.apply(AddField(f.src, structName, code))
+}
+
+// AddCase applies AddCase to the file
+func (f *File) AddCase(funcName, tag, code string) error {
+	return f.apply(AddCase(f.src, funcName, tag, code))
+}
+
+func (f *File) node(fset *token.FileSet, n ast.Node, kind, name string) Node {
+	start, end := offset(fset, n.Pos()), offset(fset, n.End())
+	return Node{
+		Kind:  kind,
+		Name:  name,
+		Start: start,
+		End:   end,
+		Text:  f.src[start:end],
+	}
+}
+
+// edit replaces the source between start and end, next to or in place of
+// node, after checking that node is still current
+func (f *File) edit(node Node, start, end int, code string) error {
+	if err := f.check(node); err != nil {
+		return err
+	}
+	return f.apply(replace(f.src, start, end, code))
+}
+
+// check returns an error if the node no longer matches the source, as
+// after an earlier edit moved or changed it
+func (f *File) check(node Node) error {
+	if node.Start < 0 || node.End < node.Start || node.End > len(f.src) {
+		return fmt.Errorf("node range %d-%d is outside the source, look it up again after editing", node.Start, node.End)
+	}
+	if f.src[node.Start:node.End] != node.Text {
+		return fmt.Errorf("node %s at %d-%d no longer matches the source, look it up again after editing", node.Name, node.Start, node.End)
+	}
+	return nil
+}
+
+func (f *File) apply(src string, err error) error {
+	if err != nil {
+		return err
+	}
+	f.src = src
+	return nil
+}
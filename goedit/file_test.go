@@ -0,0 +1,136 @@
+package goedit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFileQueries(t *testing.T) {
+	f, err := Parse(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	funcs := f.Funcs()
+	if len(funcs) != 3 {
+		t.Fatalf("Funcs() returned %d nodes, want 3", len(funcs))
+	}
+	if funcs[0].Kind != "method" || funcs[0].Name != "Start" || funcs[0].Recv != "Server" {
+		t.Errorf("Funcs()[0] = %+v, want method Server.Start", funcs[0])
+	}
+	if funcs[1].Kind != "func" || funcs[1].Name != "New" {
+		t.Errorf("Funcs()[1] = %+v, want func New", funcs[1])
+	}
+
+	types := f.Types()
+	if len(types) != 1 || types[0].Kind != "struct" || types[0].Name != "Server" {
+		t.Errorf("Types() = %+v, want struct Server", types)
+	}
+	if !strings.HasPrefix(types[0].Text, "type Server struct") {
+		t.Errorf("Types()[0].Text = %q, want full declaration", types[0].Text)
+	}
+
+	calls := f.Calls("e.GET")
+	if len(calls) != 1 {
+		t.Fatalf("Calls() returned %d nodes, want 1", len(calls))
+	}
+	if got := strings.Join(calls[0].Args, ", "); got != `"/posts", r.Posts` {
+		t.Errorf("Calls()[0].Args = %q", got)
+	}
+}
+
+func TestFileEdits(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(f *File) error
+		want string
+	}{
+		{
+			name: "insert after call",
+			edit: func(f *File) error {
+				return f.InsertAfter(f.Calls("e.GET")[0], `e.GET("/posts/:id", r.PostsShow)`)
+			},
+			want: "\te.GET(\"/posts\", r.Posts) // list\n\te.GET(\"/posts/:id\", r.PostsShow)\n",
+		},
+		{
+			name: "insert before call",
+			edit: func(f *File) error {
+				return f.InsertBefore(f.Calls("e.GET")[0], `e.Use(logger)`)
+			},
+			want: "\te.Use(logger)\n\te.GET(\"/posts\", r.Posts) // list\n",
+		},
+		{
+			name: "replace call",
+			edit: func(f *File) error {
+				return f.Replace(f.Calls("e.GET")[0], `e.GET("/articles", r.Articles)`)
+			},
+			want: "\te.GET(\"/articles\", r.Articles) // list\n",
+		},
+		{
+			name: "remove function",
+			edit: func(f *File) error {
+				return f.Remove(f.Funcs()[2])
+			},
+			want: "\treturn e\n}\n",
+		},
+		{
+			name: "shortcut edit",
+			edit: func(f *File) error {
+				return f.AddField("Server", "Port int")
+			},
+			want: "\tPort int\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse(server)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.edit(f); err != nil {
+				t.Fatalf("edit error = %v", err)
+			}
+			if !strings.Contains(f.Source(), tt.want) {
+				t.Errorf("source missing %q:\n%s", tt.want, f.Source())
+			}
+		})
+	}
+}
+
+func TestFileErrors(t *testing.T) {
+	if _, err := Parse("package"); err == nil {
+		t.Error("Parse() expected error for invalid source")
+	}
+
+	f, err := Parse(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Remove(Node{Start: 10, End: len(server) + 10}); err == nil {
+		t.Error("Remove() expected error for out of range node")
+	}
+	if err := f.Replace(f.Calls("e.GET")[0], "e.GET("); err == nil {
+		t.Error("Replace() expected error for invalid code")
+	}
+
+	// A node found before an edit that moved it is not used at its old offsets
+	get := f.Calls("e.GET")[0]
+	if err := f.InsertBefore(get, "e.Use(logger)"); err != nil {
+		t.Fatal(err)
+	}
+	before := f.Source()
+	for name, edit := range map[string]func() error{
+		"Replace":      func() error { return f.Replace(get, `e.GET("/articles", r.Articles)`) },
+		"InsertBefore": func() error { return f.InsertBefore(get, "e.Use(auth)") },
+		"InsertAfter":  func() error { return f.InsertAfter(get, "e.Use(auth)") },
+		"Remove":       func() error { return f.Remove(get) },
+	} {
+		if err := edit(); err == nil || !strings.Contains(err.Error(), "look it up again") {
+			t.Errorf("%s() with a stale node error = %v", name, err)
+		}
+	}
+	if f.Source() != before {
+		t.Errorf("stale node edits changed the source:\n%s", f.Source())
+	}
+}
@@ -0,0 +1,435 @@
+// Package goedit makes structural edits to Go source files. Edits locate
+// their target in the syntax tree rather than by marker comments, are
+// idempotent, and return gofmt-formatted source.
+package goedit
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// AddImport adds an import to src unless it is already imported. spec is an
+// import path, optionally preceded by a name: "net/http" or "h net/http".
+func AddImport(src, spec string) (string, error) {
+	name, path := "", strings.Trim(strings.TrimSpace(spec), `"`)
+	if before, after, ok := strings.Cut(path, " "); ok {
+		name, path = before, strings.Trim(strings.TrimSpace(after), `"`)
+	}
+	importLine := strconv.Quote(path)
+	if name != "" {
+		importLine = name + " " + importLine
+	}
+
+	fset, file, err := parse(src)
+	if err != nil {
+		return "", err
+	}
+
+	for _, imp := range file.Imports {
+		if imp.Path.Value == strconv.Quote(path) && (name == "" || imp.Name != nil && imp.Name.Name == name) {
+			return formatSource(src)
+		}
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+
+		if gen.Lparen.IsValid() {
+			return splice(src, offset(fset, gen.Rparen), importLine+"\n")
+		}
+
+		// Turn a single import into an import block
+		spec := src[offset(fset, gen.Specs[0].Pos()):offset(fset, gen.Specs[0].End())]
+		return replace(src, offset(fset, gen.Pos()), offset(fset, gen.End()), "import (\n"+spec+"\n"+importLine+"\n)")
+	}
+
+	// After the package clause's line, keeping a comment on it in place
+	return splice(src, lineEnd(src, offset(fset, file.Name.End())), "\n\nimport "+importLine+"\n")
+}
+
+// AddMethod appends a method declaration to src unless the receiver type
+// already has a method with the same name. The type must be declared in src.
+func AddMethod(src, typeName, code string) (string, error) {
+	_, file, err := parse(src)
+	if err != nil {
+		return "", err
+	}
+
+	method, err := parseFunc(code)
+	if err != nil {
+		return "", err
+	}
+	if method.Recv == nil {
+		return "", fmt.Errorf("code for method on %s is not a method", typeName)
+	}
+
+	if findType(file, typeName) == nil {
+		return "", fmt.Errorf("type not found: %s", typeName)
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if ok && fn.Recv != nil && receiverName(fn) == typeName && fn.Name.Name == method.Name.Name {
+			return formatSource(src)
+		}
+	}
+
+	return splice(src, len(src), "\n\n"+strings.TrimSpace(code)+"\n")
+}
+
+// AppendToFunc appends statements to the body of a function, or of a method
+// when funcName is "Type.Method". If the body ends with a return statement,
+// the code is inserted before it. Statements already present are skipped.
+func AppendToFunc(src, funcName, code string) (string, error) {
+	fset, file, err := parse(src)
+	if err != nil {
+		return "", err
+	}
+
+	fn := findFunc(file, funcName)
+	if fn == nil || fn.Body == nil {
+		return "", fmt.Errorf("function not found: %s", funcName)
+	}
+
+	snippetFset, stmts, err := parseStmts(code)
+	if err != nil {
+		return "", err
+	}
+
+	existing := map[string]bool{}
+	for _, stmt := range fn.Body.List {
+		existing[nodeString(fset, stmt)] = true
+	}
+
+	var insert []string
+	for _, stmt := range stmts {
+		if s := nodeString(snippetFset, stmt); !existing[s] {
+			insert = append(insert, s)
+		}
+	}
+	if len(insert) == 0 {
+		return formatSource(src)
+	}
+
+	// Insert after the last statement, or before a final return
+	pos := fn.Body.Lbrace + 1
+	body := fn.Body.List
+	if n := len(body); n > 0 {
+		if _, ok := body[n-1].(*ast.ReturnStmt); ok {
+			body = body[:n-1]
+		}
+	}
+	if n := len(body); n > 0 {
+		pos = body[n-1].End()
+	}
+
+	return splice(src, lineEnd(src, offset(fset, pos)), "\n"+strings.Join(insert, "\n"))
+}
+
+// AddField adds fields to a struct type, skipping fields whose names are
+// already declared
+func AddField(src, structName, code string) (string, error) {
+	fset, file, err := parse(src)
+	if err != nil {
+		return "", err
+	}
+
+	spec := findType(file, structName)
+	if spec == nil {
+		return "", fmt.Errorf("type not found: %s", structName)
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return "", fmt.Errorf("type %s is not a struct", structName)
+	}
+
+	snippetFset, fields, err := parseFields(code)
+	if err != nil {
+		return "", err
+	}
+
+	existing := map[string]bool{}
+	for _, field := range st.Fields.List {
+		existing[fieldName(fset, field)] = true
+	}
+
+	var insert []string
+	for _, field := range fields {
+		if !existing[fieldName(snippetFset, field)] {
+			insert = append(insert, fieldString(snippetFset, field))
+		}
+	}
+	if len(insert) == 0 {
+		return formatSource(src)
+	}
+
+	pos := st.Fields.Opening + 1
+	if n := len(st.Fields.List); n > 0 {
+		pos = st.Fields.List[n-1].End()
+	}
+
+	return splice(src, lineEnd(src, offset(fset, pos)), "\n"+strings.Join(insert, "\n"))
+}
+
+// AddCase adds case clauses to a switch statement in a function. If tag is
+// not empty, the switch whose tag expression matches it is used, otherwise
+// the first switch in the function. Cases whose expressions are already
+// handled are skipped. New cases are inserted before the default clause.
+func AddCase(src, funcName, tag, code string) (string, error) {
+	fset, file, err := parse(src)
+	if err != nil {
+		return "", err
+	}
+
+	fn := findFunc(file, funcName)
+	if fn == nil || fn.Body == nil {
+		return "", fmt.Errorf("function not found: %s", funcName)
+	}
+
+	var sw *ast.SwitchStmt
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if sw != nil {
+			return false
+		}
+		if s, ok := n.(*ast.SwitchStmt); ok {
+			if tag == "" || s.Tag != nil && nodeString(fset, s.Tag) == tag {
+				sw = s
+				return false
+			}
+		}
+		return true
+	})
+	if sw == nil {
+		return "", fmt.Errorf("switch %q not found in function %s", tag, funcName)
+	}
+
+	snippetFset, clauses, err := parseCases(code)
+	if err != nil {
+		return "", err
+	}
+
+	existing := map[string]bool{}
+	var defaultClause *ast.CaseClause
+	for _, stmt := range sw.Body.List {
+		clause := stmt.(*ast.CaseClause)
+		if clause.List == nil {
+			defaultClause = clause
+		}
+		for _, expr := range clause.List {
+			existing[nodeString(fset, expr)] = true
+		}
+	}
+
+	var insert []string
+	for _, clause := range clauses {
+		handled := clause.List == nil && defaultClause != nil
+		for _, expr := range clause.List {
+			if existing[nodeString(snippetFset, expr)] {
+				handled = true
+			}
+		}
+		if !handled {
+			insert = append(insert, nodeString(snippetFset, clause))
+		}
+	}
+	if len(insert) == 0 {
+		return formatSource(src)
+	}
+
+	pos := sw.Body.Rbrace
+	if defaultClause != nil {
+		pos = defaultClause.Pos()
+	}
+
+	return splice(src, offset(fset, pos), strings.Join(insert, "\n")+"\n")
+}
+
+func parse(src string) (*token.FileSet, *ast.File, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing Go source: %w", err)
+	}
+	return fset, file, nil
+}
+
+func parseFunc(code string) (*ast.FuncDecl, error) {
+	_, file, err := parse("package p\n" + code)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing code: %w", err)
+	}
+	if len(file.Decls) != 1 {
+		return nil, fmt.Errorf("code must contain exactly one function")
+	}
+	fn, ok := file.Decls[0].(*ast.FuncDecl)
+	if !ok {
+		return nil, fmt.Errorf("code must contain exactly one function")
+	}
+	return fn, nil
+}
+
+func parseStmts(code string) (*token.FileSet, []ast.Stmt, error) {
+	fset, file, err := parse("package p\nfunc _() {\n" + code + "\n}")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing code: %w", err)
+	}
+	return fset, file.Decls[0].(*ast.FuncDecl).Body.List, nil
+}
+
+func parseFields(code string) (*token.FileSet, []*ast.Field, error) {
+	fset, file, err := parse("package p\ntype _ struct {\n" + code + "\n}")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing code: %w", err)
+	}
+	spec := file.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec)
+	return fset, spec.Type.(*ast.StructType).Fields.List, nil
+}
+
+func parseCases(code string) (*token.FileSet, []*ast.CaseClause, error) {
+	fset, file, err := parse("package p\nfunc _() {\nswitch {\n" + code + "\n}\n}")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing code: %w", err)
+	}
+	sw := file.Decls[0].(*ast.FuncDecl).Body.List[0].(*ast.SwitchStmt)
+
+	var clauses []*ast.CaseClause
+	for _, stmt := range sw.Body.List {
+		clauses = append(clauses, stmt.(*ast.CaseClause))
+	}
+	return fset, clauses, nil
+}
+
+func findType(file *ast.File, name string) *ast.TypeSpec {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+				return ts
+			}
+		}
+	}
+	return nil
+}
+
+// findFunc finds a function by name, or a method by "Type.Method"
+func findFunc(file *ast.File, name string) *ast.FuncDecl {
+	typeName, funcName, isMethod := strings.Cut(name, ".")
+	if !isMethod {
+		funcName = typeName
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != funcName || (fn.Recv != nil) != isMethod {
+			continue
+		}
+		if !isMethod || receiverName(fn) == typeName {
+			return fn
+		}
+	}
+	return nil
+}
+
+// receiverName returns the type name of a method's receiver, without any
+// pointer or type parameters
+func receiverName(fn *ast.FuncDecl) string {
+	expr := fn.Recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// fieldName identifies a field by its names, or by its type if embedded
+func fieldName(fset *token.FileSet, field *ast.Field) string {
+	if len(field.Names) == 0 {
+		return nodeString(fset, field.Type)
+	}
+	var names []string
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// fieldString prints a field declaration, which go/printer does not support
+// on its own
+func fieldString(fset *token.FileSet, field *ast.Field) string {
+	var names []string
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+
+	s := nodeString(fset, field.Type)
+	if len(names) > 0 {
+		s = strings.Join(names, ", ") + " " + s
+	}
+	if field.Tag != nil {
+		s += " " + field.Tag.Value
+	}
+	return s
+}
+
+func nodeString(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+func offset(fset *token.FileSet, pos token.Pos) int {
+	return fset.Position(pos).Offset
+}
+
+// lineEnd moves offset past a trailing line comment, so code inserted after
+// a node does not separate it from its comment
+func lineEnd(src string, offset int) int {
+	rest, _, _ := strings.Cut(src[offset:], "\n")
+	trimmed := strings.TrimSpace(rest)
+	if trimmed == "" || strings.HasPrefix(trimmed, "//") {
+		return offset + len(rest)
+	}
+	return offset
+}
+
+// splice inserts text at offset and formats the result
+func splice(src string, at int, text string) (string, error) {
+	return replace(src, at, at, text)
+}
+
+// replace replaces src[start:end] with text and formats the result
+func replace(src string, start, end int, text string) (string, error) {
+	return formatSource(src[:start] + text + src[end:])
+}
+
+func formatSource(src string) (string, error) {
+	out, err := format.Source([]byte(src))
+	if err != nil {
+		return "", fmt.Errorf("error formatting edited source: %w", err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,198 @@
+package goedit
+
+import (
+	"strings"
+	"testing"
+)
+
+const server = `package web
+
+import "github.com/labstack/echo/v4"
+
+type Server struct {
+	Addr string // listen address
+}
+
+func (s *Server) Start() error {
+	return nil
+}
+
+func New() *echo.Echo {
+	e := echo.New()
+
+	e.GET("/posts", r.Posts) // list
+
+	return e
+}
+
+func handle(method string) string {
+	switch method {
+	case "GET":
+		return "get"
+	default:
+		return "other"
+	}
+}
+`
+
+func TestEdits(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(src string) (string, error)
+		want string
+	}{
+		{
+			name: "add import to single import",
+			edit: func(src string) (string, error) { return AddImport(src, "net/http") },
+			want: `package web
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+`,
+		},
+		{
+			name: "add method",
+			edit: func(src string) (string, error) {
+				return AddMethod(src, "Server", "func (s *Server) Stop() error {\nreturn nil\n}")
+			},
+			want: `
+func (s *Server) Stop() error {
+	return nil
+}
+`,
+		},
+		{
+			name: "append to function before return",
+			edit: func(src string) (string, error) {
+				return AppendToFunc(src, "New", `e.GET("/posts/:id", r.PostsShow)`)
+			},
+			want: `	e.GET("/posts", r.Posts) // list
+	e.GET("/posts/:id", r.PostsShow)
+
+	return e
+`,
+		},
+		{
+			name: "append to method",
+			edit: func(src string) (string, error) {
+				return AppendToFunc(src, "Server.Start", `s.Addr = ":8080"`)
+			},
+			want: `	s.Addr = ":8080"
+	return nil
+`,
+		},
+		{
+			name: "add field",
+			edit: func(src string) (string, error) {
+				return AddField(src, "Server", "Port int `json:\"port\"`")
+			},
+			want: "	Addr string // listen address\n	Port int    `json:\"port\"`\n}",
+		},
+		{
+			name: "add case before default",
+			edit: func(src string) (string, error) {
+				return AddCase(src, "handle", "method", "case \"POST\":\nreturn \"post\"")
+			},
+			want: `	case "POST":
+		return "post"
+	default:
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.edit(server)
+			if err != nil {
+				t.Fatalf("edit error = %v", err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("edit result missing %q:\n%s", tt.want, got)
+			}
+
+			// Applying the same edit again changes nothing
+			again, err := tt.edit(got)
+			if err != nil {
+				t.Fatalf("second edit error = %v", err)
+			}
+			if again != got {
+				t.Errorf("edit is not idempotent:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestAddImport(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		spec string
+		want string
+	}{
+		{
+			name: "no imports",
+			src:  "package main\n\nfunc main() {}\n",
+			spec: "fmt",
+			want: "package main\n\nimport \"fmt\"\n\nfunc main() {}\n",
+		},
+		{
+			name: "no imports, package comment",
+			src:  "package main // import \"example.com/cmd\"\n\nfunc main() {}\n",
+			spec: "strings",
+			want: "package main // import \"example.com/cmd\"\n\nimport \"strings\"\n\nfunc main() {}\n",
+		},
+		{
+			name: "import block",
+			src:  "package main\n\nimport (\n\t\"fmt\"\n)\n",
+			spec: "os",
+			want: "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n",
+		},
+		{
+			name: "named import",
+			src:  "package main\n\nimport (\n\t\"fmt\"\n)\n",
+			spec: "h net/http",
+			want: "package main\n\nimport (\n\t\"fmt\"\n\th \"net/http\"\n)\n",
+		},
+		{
+			name: "already imported",
+			src:  "package main\n\nimport (\n\t\"fmt\"\n)\n",
+			spec: `"fmt"`,
+			want: "package main\n\nimport (\n\t\"fmt\"\n)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AddImport(tt.src, tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("AddImport() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditErrors(t *testing.T) {
+	if _, err := AppendToFunc(server, "Missing", "x()"); err == nil {
+		t.Error("AppendToFunc() expected error for missing function")
+	}
+	if _, err := AppendToFunc(server, "New", "x("); err == nil {
+		t.Error("AppendToFunc() expected error for invalid code")
+	}
+	if _, err := AddMethod(server, "Missing", "func (m Missing) X() {}"); err == nil {
+		t.Error("AddMethod() expected error for missing type")
+	}
+	if _, err := AddMethod(server, "Server", "func X() {}"); err == nil {
+		t.Error("AddMethod() expected error for function without receiver")
+	}
+	if _, err := AddField(server, "Missing", "X int"); err == nil {
+		t.Error("AddField() expected error for missing type")
+	}
+	if _, err := AddCase(server, "handle", "other", `case "PUT":`); err == nil {
+		t.Error("AddCase() expected error for missing switch")
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"go.quinn.io/g/config"
+	"go.quinn.io/g/generator"
+)
+
+// printList writes every generator, grouped by the include it came from
+func printList(w io.Writer, generators []generator.Generator) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	defer tw.Flush()
+
+	var namespaces []string
+	byNamespace := map[string][]generator.Generator{}
+	for _, gen := range generators {
+		if _, ok := byNamespace[gen.Namespace]; !ok {
+			namespaces = append(namespaces, gen.Namespace)
+		}
+		byNamespace[gen.Namespace] = append(byNamespace[gen.Namespace], gen)
+	}
+
+	fmt.Fprintf(tw, "Available generators:\n")
+	for _, ns := range namespaces {
+		gens := byNamespace[ns]
+		name := ns
+		if name == "" {
+			name = "local"
+		}
+		fmt.Fprintf(tw, "\n%s (%s)\n", name, gens[0].Source)
+
+		for _, gen := range gens {
+			if gen.Cfg.Description == "" {
+				fmt.Fprintf(tw, "  * %s\n", gen.Usage())
+				continue
+			}
+			fmt.Fprintf(tw, "  * %s\t%s\n", gen.Usage(), gen.Cfg.Description)
+		}
+	}
+}
+
+// printHelp writes what a generator does: its arguments, the templates it
+// renders, the transforms it applies and the commands it runs afterwards
+func printHelp(w io.Writer, gen *generator.Generator) error {
+	cfg := gen.Cfg
+
+	fmt.Fprintf(w, "Usage: qg %s\n", gen.Usage())
+	if cfg.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", cfg.Description)
+	}
+	fmt.Fprintf(w, "\nSource: %s\n", gen.RootDir())
+	if cfg.Extends != "" {
+		fmt.Fprintf(w, "Extends: %s\n", cfg.Extends)
+	}
+
+	if len(cfg.Args) > 0 {
+		fmt.Fprintf(w, "\nArguments:\n")
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, arg := range cfg.Args {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", arg.Name, describeArg(arg), arg.Description)
+		}
+		tw.Flush()
+	}
+
+	if len(cfg.Use) > 0 {
+		fmt.Fprintf(w, "\nRuns generators:\n")
+		for _, use := range cfg.Use {
+			fmt.Fprintf(w, "  %s", use.Generator)
+			names := make([]string, 0, len(use.With))
+			for name := range use.With {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Fprintf(w, " %s=%s", name, use.With[name])
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	templates, err := gen.Templates()
+	if err != nil {
+		return err
+	}
+	if len(templates) > 0 {
+		fmt.Fprintf(w, "\nTemplates:\n")
+		for _, t := range templates {
+			fmt.Fprintf(w, "  %s\n", t)
+		}
+	}
+
+	if len(cfg.Transforms)+len(cfg.GoTransforms)+len(cfg.DataTransforms) > 0 {
+		fmt.Fprintf(w, "\nTransforms:\n")
+		for _, transform := range cfg.Transforms {
+			fmt.Fprintf(w, "  %s -> %s\n", transform.Function, transform.File)
+		}
+		for _, transform := range cfg.GoTransforms {
+			fmt.Fprintf(w, "  %s -> %s\n", describeGoTransform(transform), transform.File)
+		}
+		for _, transform := range cfg.DataTransforms {
+			fmt.Fprintf(w, "  %s -> %s\n", describeDataTransform(transform), transform.File)
+		}
+	}
+
+	if len(cfg.Post) > 0 {
+		fmt.Fprintf(w, "\nPost commands:\n")
+		for _, post := range cfg.Post {
+			fmt.Fprintf(w, "  %s\n", post)
+		}
+	}
+
+	return nil
+}
+
+// describeArg summarizes an argument's type and constraints
+func describeArg(arg config.Arg) string {
+	var parts []string
+
+	typ := arg.Type
+	if typ == "" {
+		typ = config.ArgString
+	}
+	if arg.Type == config.ArgEnum {
+		typ = strings.Join(arg.Enum, "|")
+	}
+	if arg.Variadic {
+		typ += "..."
+	}
+	parts = append(parts, typ)
+
+	if arg.Pattern != "" {
+		parts = append(parts, "pattern: "+arg.Pattern)
+	}
+	if arg.Default != "" {
+		parts = append(parts, "default: "+arg.Default)
+	} else if !arg.IsRequired() {
+		parts = append(parts, "optional")
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// describeGoTransform names a Go transform's action and what it targets,
+// such as "appendToFunc NewServer"
+func describeGoTransform(t config.GoTransform) string {
+	switch {
+	case t.AddImport != "":
+		return "addImport " + t.AddImport
+	case t.AddMethod != "":
+		return "addMethod " + t.AddMethod
+	case t.AppendToFunc != "":
+		return "appendToFunc " + t.AppendToFunc
+	case t.AddField != "":
+		return "addField " + t.AddField
+	case t.AddCase != "":
+		if t.Switch != "" {
+			return "addCase " + t.AddCase + " switch " + t.Switch
+		}
+		return "addCase " + t.AddCase
+	}
+	return "goTransform"
+}
+
+// describeDataTransform names a data transform's action and path, such as
+// "append services.web.ports"
+func describeDataTransform(t config.DataTransform) string {
+	switch {
+	case t.Set != "":
+		return "set " + t.Set
+	case t.Append != "":
+		return "append " + t.Append
+	case t.Merge != "":
+		return "merge " + t.Merge
+	}
+	return "dataTransform"
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.quinn.io/g/config"
+	"go.quinn.io/g/generator"
+)
+
+func TestPrintHelp_Transforms(t *testing.T) {
+	gen := generator.New(config.Generator{
+		Name:       "route",
+		Transforms: config.Transforms{{Function: "addRoute", File: "routes.go"}},
+		GoTransforms: []config.GoTransform{
+			{File: "internal/web/server.go", AppendToFunc: "NewServer", Code: "e.GET()"},
+			{File: "cmd/main.go", AddCase: "run", Switch: "cmd", Code: `case "serve":`},
+		},
+		DataTransforms: []config.DataTransform{
+			{File: "g.yaml", Append: "generators", Value: "name: x"},
+		},
+	}, "route", t.TempDir())
+
+	var out bytes.Buffer
+	if err := printHelp(&out, &gen); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+Transforms:
+  addRoute -> routes.go
+  appendToFunc NewServer -> internal/web/server.go
+  addCase run switch cmd -> cmd/main.go
+  append generators -> g.yaml
+`
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("printHelp() = \n%s\nwant transforms\n%s", out.String(), want)
+	}
+}
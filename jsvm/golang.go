@@ -0,0 +1,111 @@
+package jsvm
+
+import (
+	"fmt"
+
+	"github.com/dop251/goja"
+	"go.quinn.io/g/goedit"
+)
+
+// registerGo defines the go module, which lets transforms query and edit Go
+// source structurally:
+//
+//	const file = go.parse(fileData)
+//	const [last] = file.calls("e.GET").slice(-1)
+//	file.insertAfter(last, `e.GET("/posts", r.Posts)`)
+//	return file.source()
+func (v *VM) registerGo() error {
+	module := v.vm.NewObject()
+
+	if err := module.Set("parse", func(src string) (*goja.Object, error) {
+		file, err := goedit.Parse(src)
+		if err != nil {
+			return nil, err
+		}
+		return v.goFile(file)
+	}); err != nil {
+		return err
+	}
+
+	return v.vm.Set("go", module)
+}
+
+// goFile wraps a goedit.File in a JavaScript object. Nodes are passed to
+// JavaScript as plain objects and read back from their start, end and text.
+func (v *VM) goFile(file *goedit.File) (*goja.Object, error) {
+	obj := v.vm.NewObject()
+
+	methods := map[string]any{
+		"source":   file.Source,
+		"toString": file.Source,
+		"funcs":    func() []map[string]any { return fromNodes(file.Funcs()) },
+		"types":    func() []map[string]any { return fromNodes(file.Types()) },
+		"calls": func(name string) []map[string]any {
+			return fromNodes(file.Calls(name))
+		},
+		"replace": func(node map[string]any, code string) error {
+			return file.Replace(toNode(node), code)
+		},
+		"insertBefore": func(node map[string]any, code string) error {
+			return file.InsertBefore(toNode(node), code)
+		},
+		"insertAfter": func(node map[string]any, code string) error {
+			return file.InsertAfter(toNode(node), code)
+		},
+		"remove": func(node map[string]any) error {
+			return file.Remove(toNode(node))
+		},
+		"addImport":    file.AddImport,
+		"addMethod":    file.AddMethod,
+		"appendToFunc": file.AppendToFunc,
+		"addField":     file.AddField,
+		"addCase":      file.AddCase,
+	}
+
+	for name, fn := range methods {
+		if err := obj.Set(name, fn); err != nil {
+			return nil, fmt.Errorf("error defining go file method %s: %w", name, err)
+		}
+	}
+
+	return obj, nil
+}
+
+func fromNodes(nodes []goedit.Node) []map[string]any {
+	out := make([]map[string]any, len(nodes))
+	for i, n := range nodes {
+		out[i] = map[string]any{
+			"kind":  n.Kind,
+			"name":  n.Name,
+			"recv":  n.Recv,
+			"args":  n.Args,
+			"start": n.Start,
+			"end":   n.End,
+			"text":  n.Text,
+		}
+	}
+	return out
+}
+
+func toNode(obj map[string]any) goedit.Node {
+	name, _ := obj["name"].(string)
+	text, _ := obj["text"].(string)
+	return goedit.Node{
+		Name:  name,
+		Start: toInt(obj["start"]),
+		End:   toInt(obj["end"]),
+		Text:  text,
+	}
+}
+
+func toInt(v any) int {
+	switch n := v.(type) {
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	case int:
+		return n
+	}
+	return -1
+}
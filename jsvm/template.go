@@ -0,0 +1,109 @@
+package jsvm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dop251/goja"
+)
+
+// RenderTemplate renders text as a JavaScript template literal. Each ${...}
+// expression is evaluated with the config values in scope, alongside the
+// functions defined in config.js. Everything else, including backticks and
+// backslashes, is copied as is; write \${ for a literal "${".
+func (v *VM) RenderTemplate(text string, config map[string]any) (string, error) {
+	parts, exprs, err := splitTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	if len(exprs) == 0 {
+		return parts[0], nil
+	}
+
+	calls := make([]string, len(exprs))
+	for i, expr := range exprs {
+		calls[i] = "String((" + expr + "))"
+	}
+	src := "(function (G_TEMPLATE_CONFIG) { with (G_TEMPLATE_CONFIG) { return [" +
+		strings.Join(calls, ", ") + "]; } })"
+	fn, err := v.vm.RunString(src)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template expression: %w", err)
+	}
+
+	call, ok := goja.AssertFunction(fn)
+	if !ok {
+		return "", fmt.Errorf("error parsing template expression")
+	}
+
+	result, err := call(goja.Undefined(), v.vm.ToValue(config))
+	if err != nil {
+		return "", fmt.Errorf("error evaluating template expression: %w", err)
+	}
+
+	var values []string
+	if err := v.vm.ExportTo(result, &values); err != nil {
+		return "", fmt.Errorf("error evaluating template expression: %w", err)
+	}
+
+	var out strings.Builder
+	for i, part := range parts {
+		out.WriteString(part)
+		if i < len(values) {
+			out.WriteString(values[i])
+		}
+	}
+	return out.String(), nil
+}
+
+// splitTemplate splits text into the literal parts around ${...}
+// expressions, returning one more part than expressions
+func splitTemplate(text string) ([]string, []string, error) {
+	var parts, exprs []string
+	var literal strings.Builder
+
+	for i := 0; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], `\${`):
+			literal.WriteString("${")
+			i += 2
+		case strings.HasPrefix(text[i:], "${"):
+			end, err := exprEnd(text, i+2)
+			if err != nil {
+				return nil, nil, err
+			}
+			parts = append(parts, literal.String())
+			literal.Reset()
+			exprs = append(exprs, text[i+2:end])
+			i = end
+		default:
+			literal.WriteByte(text[i])
+		}
+	}
+
+	return append(parts, literal.String()), exprs, nil
+}
+
+// exprEnd returns the index of the brace closing the expression starting
+// at start, skipping braces inside nested blocks and string literals
+func exprEnd(text string, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i, nil
+			}
+			depth--
+		case '"', '\'', '`':
+			for i++; i < len(text) && text[i] != c; i++ {
+				if text[i] == '\\' {
+					i++
+				}
+			}
+		}
+	}
+	return 0, fmt.Errorf("unterminated template expression at offset %d", start-2)
+}
@@ -0,0 +1,72 @@
+package jsvm
+
+import (
+	"testing"
+)
+
+func TestVM_RenderTemplate(t *testing.T) {
+	vm := New()
+	if _, err := vm.RunConfigFile("does-not-exist.js"); err != nil {
+		t.Fatal(err)
+	}
+
+	config := map[string]any{
+		"name":   "posts",
+		"fields": []any{"id", "title"},
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "no expressions",
+			text: "replicas: {{ .Values.replicas }}\n",
+			want: "replicas: {{ .Values.replicas }}\n",
+		},
+		{
+			name: "config values",
+			text: "name: ${name}\nimage: {{ .Values.image }}\n",
+			want: "name: posts\nimage: {{ .Values.image }}\n",
+		},
+		{
+			name: "expressions with braces and strings",
+			text: "${fields.map(f => { return `\"${f}\"` }).join(\", \")}",
+			want: `"id", "title"`,
+		},
+		{
+			name: "helpers from config.js",
+			text: "type ${convertCase(\"pascal\", name)} struct{}",
+			want: "type Posts struct{}",
+		},
+		{
+			name: "backslashes and backticks are kept",
+			text: "echo `date`\\n \\${name}",
+			want: "echo `date`\\n ${name}",
+		},
+		{
+			name:    "unterminated expression",
+			text:    "${name",
+			wantErr: true,
+		},
+		{
+			name:    "unknown value",
+			text:    "${missing}",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := vm.RenderTemplate(tt.text, config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RenderTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,8 +4,12 @@ import (
 	_ "embed"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
 
 	"github.com/dop251/goja"
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/parser"
 )
 
 //go:embed js/convertCase.js
@@ -18,13 +22,19 @@ type VM struct {
 
 // New creates a new JavaScript VM instance
 func New() *VM {
-	return &VM{
+	v := &VM{
 		vm: goja.New(),
 	}
+
+	if err := v.registerGo(); err != nil {
+		panic(fmt.Errorf("error registering go module: %w", err))
+	}
+
+	return v
 }
 
 // SetConfig sets the configuration in the VM environment
-func (v *VM) SetConfig(config map[string]string) error {
+func (v *VM) SetConfig(config map[string]any) error {
 	if err := v.vm.Set("G_CONFIG_INPUT", config); err != nil {
 		return fmt.Errorf("error setting config input: %w", err)
 	}
@@ -32,7 +42,7 @@ func (v *VM) SetConfig(config map[string]string) error {
 }
 
 // RunConfigFile executes a JavaScript config file and returns the resulting configuration
-func (v *VM) RunConfigFile(configPath string) (map[string]string, error) {
+func (v *VM) RunConfigFile(configPath string) (map[string]any, error) {
 	// Run the convertCase.js helper
 	if _, err := v.vm.RunString(jsConvertCase); err != nil {
 		return nil, fmt.Errorf("error running convertCase.js: %w", err)
@@ -47,7 +57,7 @@ func (v *VM) RunConfigFile(configPath string) (map[string]string, error) {
 		return nil, fmt.Errorf("error reading config file: %w", err)
 	}
 
-	if _, err := v.vm.RunString(string(configData)); err != nil {
+	if err := v.runScoped(configPath, string(configData)); err != nil {
 		return nil, fmt.Errorf("error running config file: %w", err)
 	}
 
@@ -57,11 +67,73 @@ func (v *VM) RunConfigFile(configPath string) (map[string]string, error) {
 		return nil, fmt.Errorf("error running config function: %w", err)
 	}
 
-	return exportToStringMap(result)
+	return exportToMap(result)
+}
+
+// runScoped runs a config file in a function scope of its own and then
+// makes its top-level declarations global, so config files of generators
+// extending each other can declare the same const, let or class names.
+// Functions of each file keep seeing their own file's declarations.
+func (v *VM) runScoped(name, src string) error {
+	program, err := parser.ParseFile(nil, name, src, 0)
+	if err != nil {
+		return err
+	}
+
+	var exports []string
+	for _, name := range topLevelNames(program) {
+		exports = append(exports, fmt.Sprintf("%q: %s", name, name))
+	}
+	// The file starts on the wrapper's first line to keep line numbers
+	wrapped := "(function () {" + src + "\n;return {" + strings.Join(exports, ", ") + "};\n})()"
+
+	result, err := v.vm.RunString(wrapped)
+	if err != nil {
+		return err
+	}
+
+	declared := result.ToObject(v.vm)
+	for _, key := range declared.Keys() {
+		if err := v.vm.Set(key, declared.Get(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topLevelNames returns the names declared at the top level of a program by
+// function, class, var, let and const declarations
+func topLevelNames(program *ast.Program) []string {
+	var names []string
+	for _, stmt := range program.Body {
+		switch stmt := stmt.(type) {
+		case *ast.FunctionDeclaration:
+			names = append(names, stmt.Function.Name.Name.String())
+		case *ast.ClassDeclaration:
+			names = append(names, stmt.Class.Name.Name.String())
+		case *ast.VariableStatement:
+			names = append(names, bindingNames(stmt.List)...)
+		case *ast.LexicalDeclaration:
+			names = append(names, bindingNames(stmt.List)...)
+		}
+	}
+	return names
+}
+
+// bindingNames returns the names of the bindings declared as plain
+// identifiers; destructured bindings stay local to their file
+func bindingNames(list []*ast.Binding) []string {
+	var names []string
+	for _, binding := range list {
+		if id, ok := binding.Target.(*ast.Identifier); ok {
+			names = append(names, id.Name.String())
+		}
+	}
+	return names
 }
 
 // RunTransform executes a JavaScript transform function on the given input
-func (v *VM) RunTransform(jsFunction string, fileInput string, config map[string]string) (string, error) {
+func (v *VM) RunTransform(jsFunction string, fileInput string, config map[string]any) (string, error) {
 	if err := v.vm.Set("G_FILE_INPUT", fileInput); err != nil {
 		return "", fmt.Errorf("error setting file input: %w", err)
 	}
@@ -78,27 +150,90 @@ func (v *VM) RunTransform(jsFunction string, fileInput string, config map[string
 	return result.String(), nil
 }
 
-// exportToStringMap converts a goja.Value to a map[string]string
-func exportToStringMap(v goja.Value) (map[string]string, error) {
-	export := v.Export()
+// Helpers returns the functions of the helpers object defined by the config
+// file, wrapped so they can be called from templates. Each call runs in this
+// VM, with arguments and results converted between Go and JavaScript.
+func (v *VM) Helpers() (map[string]any, error) {
+	value := v.vm.Get("helpers")
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return nil, nil
+	}
 
-	// Try direct conversion to map[string]string
-	if smap, ok := export.(map[string]string); ok {
-		return smap, nil
+	obj, ok := value.(*goja.Object)
+	if !ok {
+		return nil, fmt.Errorf("helpers must be an object, got %v", value)
 	}
 
-	// Try conversion from map[string]interface{}
-	if imap, ok := export.(map[string]interface{}); ok {
-		smap := make(map[string]string)
-		for k, v := range imap {
-			str, ok := v.(string)
-			if !ok {
-				return nil, fmt.Errorf("value for key %s is not a string", k)
-			}
-			smap[k] = str
+	helpers := map[string]any{}
+	for _, name := range obj.Keys() {
+		fn, ok := goja.AssertFunction(obj.Get(name))
+		if !ok {
+			return nil, fmt.Errorf("helper %s is not a function", name)
+		}
+		if !helperName.MatchString(name) {
+			return nil, fmt.Errorf("helper %s is not a valid template function name", name)
+		}
+
+		helpers[name] = v.wrapHelper(name, fn)
+	}
+
+	return helpers, nil
+}
+
+var helperName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (v *VM) wrapHelper(name string, fn goja.Callable) func(args ...any) (any, error) {
+	return func(args ...any) (any, error) {
+		jsArgs := make([]goja.Value, len(args))
+		for i, arg := range args {
+			jsArgs[i] = v.vm.ToValue(arg)
+		}
+
+		result, err := fn(goja.Undefined(), jsArgs...)
+		if err != nil {
+			return nil, fmt.Errorf("error running helper %s: %w", name, err)
 		}
-		return smap, nil
+		return result.Export(), nil
 	}
+}
+
+// exportToMap converts a goja.Value holding a plain object to a map. Values
+// may be strings, numbers, booleans, null, arrays and nested objects.
+func exportToMap(v goja.Value) (map[string]any, error) {
+	m, ok := v.Export().(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("config must return an object, got %v", v)
+	}
+
+	for k, val := range m {
+		if err := checkValue(k, val); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
 
-	return nil, fmt.Errorf("unable to convert value to map[string]string")
+// checkValue reports values that cannot be used in templates, such as
+// functions, naming the key they were found at
+func checkValue(key string, v any) error {
+	switch val := v.(type) {
+	case nil, string, bool, int64, float64:
+		return nil
+	case []any:
+		for i, item := range val {
+			if err := checkValue(fmt.Sprintf("%s[%d]", key, i), item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]any:
+		for k, item := range val {
+			if err := checkValue(key+"."+k, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("value for key %s is not a string, number, boolean, array or object", key)
+	}
 }
@@ -4,12 +4,13 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
 func TestVM_SetConfig(t *testing.T) {
 	vm := New()
-	config := map[string]string{
+	config := map[string]any{
 		"key": "value",
 	}
 
@@ -37,7 +38,7 @@ func TestVM_RunConfigFile(t *testing.T) {
 	}
 
 	vm := New()
-	config := map[string]string{
+	config := map[string]any{
 		"key1": "value1",
 	}
 	if err := vm.SetConfig(config); err != nil {
@@ -49,7 +50,7 @@ func TestVM_RunConfigFile(t *testing.T) {
 		t.Errorf("RunConfigFile() error = %v", err)
 	}
 
-	expected := map[string]string{
+	expected := map[string]any{
 		"key1": "value1",
 		"key2": "value2",
 	}
@@ -58,9 +59,59 @@ func TestVM_RunConfigFile(t *testing.T) {
 	}
 }
 
+func TestVM_RunConfigFileScopes(t *testing.T) {
+	tmpDir := t.TempDir()
+	basePath := filepath.Join(tmpDir, "base.js")
+	childPath := filepath.Join(tmpDir, "child.js")
+
+	base := `
+		const prefix = "base";
+		function basePrefix() { return prefix; }
+		function config(input) { return { base: prefix }; }
+	`
+	child := `
+		const prefix = "child";
+		class Name {}
+		function config(input) { return { base: input.base, child: prefix, fromBase: basePrefix() }; }
+	`
+	if err := os.WriteFile(basePath, []byte(base), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(childPath, []byte(child), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vm := New()
+	config := map[string]any{}
+	for _, path := range []string{basePath, childPath} {
+		if err := vm.SetConfig(config); err != nil {
+			t.Fatal(err)
+		}
+		result, err := vm.RunConfigFile(path)
+		if err != nil {
+			t.Fatalf("RunConfigFile(%s) error = %v", filepath.Base(path), err)
+		}
+		for k, v := range result {
+			config[k] = v
+		}
+	}
+
+	// Each file's functions see that file's declarations
+	expected := map[string]any{"base": "base", "child": "child", "fromBase": "base"}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("RunConfigFile() = %v, want %v", config, expected)
+	}
+
+	// Top-level declarations are available to transforms and templates
+	result, err := vm.RunTransform("basePrefix", "", nil)
+	if err != nil || result != "base" {
+		t.Errorf("RunTransform() = %q, %v, want %q", result, err, "base")
+	}
+}
+
 func TestVM_RunTransform(t *testing.T) {
 	vm := New()
-	config := map[string]string{
+	config := map[string]any{
 		"key": "value",
 	}
 	if err := vm.SetConfig(config); err != nil {
@@ -86,26 +137,42 @@ func TestVM_RunTransform(t *testing.T) {
 	}
 }
 
-func TestExportToStringMap(t *testing.T) {
+func TestExportToMap(t *testing.T) {
 	vm := New()
 
 	tests := []struct {
 		name    string
 		js      string
-		want    map[string]string
-		wantErr bool
+		want    map[string]any
+		wantErr string
 	}{
 		{
 			name: "direct string map",
 			js:   `({key: "value"})`,
-			want: map[string]string{
+			want: map[string]any{
 				"key": "value",
 			},
 		},
 		{
-			name:    "invalid value type",
-			js:      `({key: 123})`,
-			wantErr: true,
+			name: "structured values",
+			js:   `({count: 2, ratio: 0.5, enabled: true, none: null, fields: ["id", {name: "title"}]})`,
+			want: map[string]any{
+				"count":   int64(2),
+				"ratio":   0.5,
+				"enabled": true,
+				"none":    nil,
+				"fields":  []any{"id", map[string]any{"name": "title"}},
+			},
+		},
+		{
+			name:    "function value",
+			js:      `({fields: [{name: "id", fn: function() {}}]})`,
+			wantErr: "value for key fields[0].fn is not a string, number, boolean, array or object",
+		},
+		{
+			name:    "not an object",
+			js:      `"value"`,
+			wantErr: "config must return an object, got value",
 		},
 	}
 
@@ -116,14 +183,168 @@ func TestExportToStringMap(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			got, err := exportToStringMap(val)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("exportToStringMap() error = %v, wantErr %v", err, tt.wantErr)
+			got, err := exportToMap(val)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("exportToMap() error = %v, want %v", err, tt.wantErr)
+				}
 				return
 			}
-			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("exportToStringMap() = %v, want %v", got, tt.want)
+			if err != nil {
+				t.Fatalf("exportToMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("exportToMap() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestVM_Helpers(t *testing.T) {
+	vm := New()
+
+	// No helpers defined
+	helpers, err := vm.Helpers()
+	if err != nil || helpers != nil {
+		t.Errorf("Helpers() = %v, %v, want none", helpers, err)
+	}
+
+	js := `
+		const prefix = "r."
+		var helpers = {
+			handler: (name) => prefix + name,
+			sum: (...n) => n.reduce((a, b) => a + b, 0),
+			fail: () => { throw new Error("boom") },
+		}
+	`
+	if _, err := vm.vm.RunString(js); err != nil {
+		t.Fatal(err)
+	}
+
+	helpers, err = vm.Helpers()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := helpers["handler"].(func(args ...any) (any, error))
+	if got, err := handler("Posts"); err != nil || got != "r.Posts" {
+		t.Errorf("handler() = %v, %v, want r.Posts", got, err)
+	}
+
+	sum := helpers["sum"].(func(args ...any) (any, error))
+	if got, err := sum(1, 2, 3); err != nil || got != int64(6) {
+		t.Errorf("sum() = %v, %v, want 6", got, err)
+	}
+
+	fail := helpers["fail"].(func(args ...any) (any, error))
+	if _, err := fail(); err == nil {
+		t.Error("fail() expected error")
+	}
+
+	// Invalid helpers
+	for _, js := range []string{
+		`helpers = "nope"`,
+		`helpers = { value: 1 }`,
+		`helpers = { "my-helper": () => 1 }`,
+	} {
+		if _, err := vm.vm.RunString(js); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := vm.Helpers(); err == nil {
+			t.Errorf("Helpers() expected error for %s", js)
+		}
+	}
+}
+
+func TestVM_GoModule(t *testing.T) {
+	vm := New()
+
+	src := `package web
+
+func NewServer() *Echo {
+	e := New()
+
+	e.GET("/posts", r.Posts) // list
+	e.POST("/posts", r.PostsCreate)
+
+	return e
+}
+
+type Routes struct{}
+`
+
+	jsFunction := `function transform(input, config) {
+		const file = go.parse(input)
+
+		const funcs = file.funcs()
+		if (funcs.length !== 1 || funcs[0].name !== "NewServer") {
+			throw new Error("unexpected funcs: " + JSON.stringify(funcs))
+		}
+
+		const types = file.types()
+		if (types.length !== 1 || types[0].kind !== "struct") {
+			throw new Error("unexpected types: " + JSON.stringify(types))
+		}
+
+		const [get] = file.calls("e.GET")
+		if (get.args[0] !== '"/posts"') {
+			throw new Error("unexpected args: " + get.args)
+		}
+		file.insertAfter(get, 'e.GET("/posts/:id", r.' + config.funcName + ')')
+
+		// Nodes must be looked up again after an edit
+		const [post] = file.calls("e.POST")
+		file.remove(post)
+
+		file.addImport("net/http")
+		return file.source()
+	}`
+
+	if _, err := vm.vm.RunString(jsFunction); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := vm.RunTransform("transform", src, map[string]any{"funcName": "PostsShow"})
+	if err != nil {
+		t.Fatalf("RunTransform() error = %v", err)
+	}
+
+	expected := `package web
+
+import "net/http"
+
+func NewServer() *Echo {
+	e := New()
+
+	e.GET("/posts", r.Posts) // list
+	e.GET("/posts/:id", r.PostsShow)
+
+	return e
+}
+
+type Routes struct{}
+`
+	if result != expected {
+		t.Errorf("RunTransform() = %v, want %v", result, expected)
+	}
+
+	// Reusing a node after an edit moved it is an error
+	stale := `function staleTransform(input) {
+		const file = go.parse(input)
+		const [get] = file.calls("e.GET")
+		file.insertBefore(get, 'e.Use(logger)')
+		file.replace(get, 'e.GET("/articles", r.Articles)')
+		return file.source()
+	}`
+	if _, err := vm.vm.RunString(stale); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.RunTransform("staleTransform", src, nil); err == nil || !strings.Contains(err.Error(), "look it up again") {
+		t.Errorf("RunTransform() with a stale node error = %v", err)
+	}
+
+	// Parse errors are thrown into JavaScript
+	if _, err := vm.vm.RunString(`go.parse("package")`); err == nil {
+		t.Error("go.parse() expected error for invalid source")
+	}
+}
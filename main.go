@@ -1,13 +1,17 @@
 package main
 
 import (
+	"bufio"
 	_ "embed"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 
+	"go.quinn.io/g/config"
 	"go.quinn.io/g/fileops"
 	"go.quinn.io/g/generator"
+	"go.quinn.io/g/template"
 	"go.quinn.io/g/util"
 )
 
@@ -25,11 +29,20 @@ func main() {
 	flag.StringVar(&outDir, "out", ".", "Output directory.")
 	var new bool
 	flag.BoolVar(&new, "new", false, "Target a new dir for generation.")
+	var force bool
+	flag.BoolVar(&force, "force", false, "Overwrite existing files that differ from the rendered templates.")
+	var skipExisting bool
+	flag.BoolVar(&skipExisting, "skip-existing", false, "Leave existing files that differ from the rendered templates untouched.")
+	var dryRun bool
+	flag.BoolVar(&dryRun, "dry-run", os.Getenv("DRY_RUN") == "true", "Print a diff of the changes instead of writing them.")
 
 	// Custom help message
 	flag.Usage = func() {
-		fileops.Print("Usage of %s:\n", os.Args[0])
-		fileops.Print("This is a sample application to demonstrate custom help text.\n\n")
+		fileops.Print("Usage: %s [options] <generator> [args...]\n", os.Args[0])
+		fileops.Print("       %s [options] list\n", os.Args[0])
+		fileops.Print("       %s [options] help <generator>\n\n", os.Args[0])
+		fileops.Print("Generates code from the templates in the .g directory.\n")
+		fileops.Print("Arguments can be positional or given as --name value.\n\n")
 		fileops.Print("Options:\n")
 		flag.PrintDefaults()
 	}
@@ -48,25 +61,33 @@ func main() {
 	args := flag.Args()
 
 	if len(args) == 0 {
-		fileops.Print("Available generators:\n")
-		for _, gen := range generators {
-			fileops.Print("* " + gen.Cmd)
-			args := gen.Cfg.Args
-
-			if len(args) > 0 {
-				for _, arg := range args {
-					fileops.Print(" [%s]", arg)
-				}
-			}
-			fileops.Print("\n")
-		}
+		printList(os.Stdout, generators)
 		return
 	}
 
 	args, gName := shift(args)
+
+	switch gName {
+	case "list":
+		printList(os.Stdout, generators)
+		return
+	case "help":
+		if len(args) == 0 {
+			flag.Usage()
+			return
+		}
+		gen, err := generator.Find(generators, args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := printHelp(os.Stdout, gen); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	// gen := generator.New(rootDir, outDir, jsConvertCase)
 
-	gConfig := map[string]string{
+	gConfig := map[string]any{
 		"outDir": outDir,
 	}
 
@@ -76,16 +97,77 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if len(args) < len(gen.Cfg.Args) {
-		log.Fatalf("Missing arguments: %v", gen.Cfg.Args)
+	switch {
+	case force && skipExisting:
+		log.Fatal("-force and -skip-existing cannot be used together")
+	case force:
+		gen.Conflict = template.ConflictOverwrite
+	case skipExisting:
+		gen.Conflict = template.ConflictSkip
 	}
 
 	// Set up generator config from arguments
-	for i, arg := range gen.Cfg.Args {
-		gConfig[arg] = args[i]
+	values, err := generator.ParseArgs(gen.Cfg.Args, args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var missing []config.Arg
+	for _, arg := range gen.Cfg.Args {
+		if value, ok := values[arg.Name]; ok {
+			gConfig[arg.Name] = value
+		} else if arg.IsRequired() {
+			missing = append(missing, arg)
+		}
+	}
+
+	// Ask for missing arguments when running interactively
+	if len(missing) > 0 {
+		if !fileops.IsTerminal(os.Stdin) {
+			var names []string
+			for _, arg := range missing {
+				names = append(names, arg.Name)
+			}
+			log.Fatalf("Missing arguments: %v", names)
+		}
+
+		in := bufio.NewReader(os.Stdin)
+		for _, arg := range missing {
+			value, err := generator.PromptArg(in, arg)
+			if err != nil {
+				log.Fatal(err)
+			}
+			gConfig[arg.Name] = value
+		}
+	}
+
+	var overlay *fileops.Overlay
+	if dryRun {
+		overlay = fileops.StartDryRun()
+		defer fileops.StopDryRun()
 	}
 
 	if _, err := gen.Run(generators, gConfig, outDir); err != nil {
 		log.Fatal(err)
 	}
+
+	if overlay != nil {
+		printDryRun(overlay)
+	}
+}
+
+// printDryRun writes the diff of every touched file to stdout, followed by
+// the post commands that would have been run.
+func printDryRun(overlay *fileops.Overlay) {
+	fmt.Print(overlay.Diff())
+
+	commands := overlay.Commands()
+	if len(commands) == 0 {
+		return
+	}
+
+	fmt.Print("\nPost commands:\n")
+	for _, c := range commands {
+		fmt.Printf("  (in %s) %s\n", c.Dir, c.Cmd)
+	}
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"go.quinn.io/g/fileops"
 	"mvdan.cc/sh/v3/interp"
 	"mvdan.cc/sh/v3/syntax"
 )
@@ -24,8 +25,8 @@ func New(workDir string) *Runner {
 
 // Run executes a shell command in the specified working directory
 func (r *Runner) Run(cmd string) error {
-	if os.Getenv("DRY_RUN") == "true" {
-		fmt.Printf("DRY_RUN: running %s\n", cmd)
+	if o := fileops.DryRun(); o != nil {
+		o.AddCommand(r.workDir, cmd)
 		return nil
 	}
 
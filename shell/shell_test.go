@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"go.quinn.io/g/fileops"
 )
 
 func TestRunner_Run(t *testing.T) {
@@ -11,12 +13,16 @@ func TestRunner_Run(t *testing.T) {
 	tmpDir := t.TempDir()
 	runner := New(tmpDir)
 
-	// Test with DRY_RUN=true
-	os.Setenv("DRY_RUN", "true")
+	// Test in a dry run
+	overlay := fileops.StartDryRun()
 	if err := runner.Run("echo test"); err != nil {
-		t.Errorf("Run() error = %v with DRY_RUN=true", err)
+		t.Errorf("Run() error = %v in dry run", err)
+	}
+	fileops.StopDryRun()
+
+	if cmds := overlay.Commands(); len(cmds) != 1 || cmds[0].Cmd != "echo test" {
+		t.Errorf("Run() in dry run recorded %v, want [echo test]", cmds)
 	}
-	os.Unsetenv("DRY_RUN")
 
 	// Test actual command execution
 	testFile := filepath.Join(tmpDir, "test.txt")
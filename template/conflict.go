@@ -0,0 +1,81 @@
+package template
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.quinn.io/g/fileops"
+)
+
+// ConflictPolicy decides what happens when a rendered file would replace an
+// existing file with different content
+type ConflictPolicy int
+
+const (
+	// ConflictAsk prompts the user when stdin is a terminal, and fails otherwise
+	ConflictAsk ConflictPolicy = iota
+	// ConflictOverwrite replaces the existing file
+	ConflictOverwrite
+	// ConflictSkip leaves the existing file untouched
+	ConflictSkip
+)
+
+// ErrConflict is returned when a file conflicts and there is no way to ask
+// the user how to resolve it
+var ErrConflict = errors.New("target exists with different content (use -force or -skip-existing)")
+
+// resolveConflict reports whether the rendered content should be written to
+// targetPath, which already exists with different content
+func (p *Processor) resolveConflict(policy ConflictPolicy, targetPath, existing, rendered string) (bool, error) {
+	switch policy {
+	case ConflictOverwrite:
+		return true, nil
+	case ConflictSkip:
+		fileops.Print("Skipping existing file: %s\n", targetPath)
+		return false, nil
+	}
+
+	if fileops.DryRun() != nil {
+		fileops.Print("Conflict: %s differs from the rendered template\n", targetPath)
+		return true, nil
+	}
+
+	if p.stdin == nil {
+		if !fileops.IsTerminal(os.Stdin) {
+			return false, fmt.Errorf("%s: %w", targetPath, ErrConflict)
+		}
+		p.stdin = bufio.NewReader(os.Stdin)
+	}
+
+	for {
+		fileops.Print("Conflict: %s already exists.\n", targetPath)
+		fileops.Print("[s]kip, [o]verwrite, show [d]iff, [k]eep existing as .orig and overwrite? ")
+
+		answer, err := p.stdin.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return false, fmt.Errorf("error reading answer: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "s", "skip":
+			return false, nil
+		case "o", "overwrite":
+			return true, nil
+		case "d", "diff":
+			fileops.Print("%s", fileops.Diff(targetPath, targetPath+" (rendered)", existing, rendered))
+		case "k", "keep":
+			if err := fileops.WriteFile(targetPath+".orig", existing); err != nil {
+				return false, fmt.Errorf("error writing %s.orig: %w", targetPath, err)
+			}
+			return true, nil
+		}
+
+		if err == io.EOF {
+			return false, fmt.Errorf("%s: %w", targetPath, ErrConflict)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Output is a file a template path produces, with the config it is
+// rendered with
+type Output struct {
+	Target string
+	Config map[string]any
+}
+
+var eachPlaceholder = regexp.MustCompile(`\[([^\[\]]+)\.\.\.\]`)
+
+// Expand resolves a template path to the files it produces. A path with a
+// [key...] placeholder, such as [fields...].go.tpl, produces one file per
+// item of the list key, named after the item. Each item is rendered with
+// the item in .item and its position in .index. Items that leave a path
+// segment empty are skipped. Other paths produce a single file, as given by
+// ProcessPath.
+func (p *Processor) Expand(templatePath string, config map[string]any) ([]Output, error) {
+	matches := eachPlaceholder.FindAllStringSubmatch(templatePath, -1)
+	if len(matches) == 0 {
+		target, err := p.ProcessPath(templatePath, config)
+		if err != nil {
+			return nil, err
+		}
+		return []Output{{Target: target, Config: config}}, nil
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("%s: only one [key...] placeholder is allowed in a path", templatePath)
+	}
+
+	items, err := listValue(config, matches[0][1])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", templatePath, err)
+	}
+
+	itemPath := strings.Replace(templatePath, matches[0][0], "[item]", 1)
+	var outputs []Output
+	for i, item := range items {
+		itemConfig := withItem(config, item, i)
+		target, err := p.ProcessPath(itemPath, itemConfig)
+		if errors.Is(err, ErrSkipPath) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, Output{Target: target, Config: itemConfig})
+	}
+	return outputs, nil
+}
+
+var eachLine = regexp.MustCompile(`(?m)^each:\s*["']?([\w.-]+)["']?\s*$`)
+
+// eachKey returns the list named by each in a template's frontmatter. It is
+// read before the frontmatter is rendered, so it cannot use the config.
+func eachKey(sourcePath, data string) string {
+	if !strings.HasSuffix(sourcePath, ".tpl") {
+		return ""
+	}
+
+	header, _, ok := splitFrontmatter(data)
+	if !ok {
+		return ""
+	}
+
+	m := eachLine.FindStringSubmatch(header)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+var eachField = regexp.MustCompile(`(?m)^each:`)
+
+var errTemplatedEach = errors.New(`each must name a config list as is, such as "each: endpoints", not a template`)
+
+// hasEach reports whether a template's frontmatter sets each at all, even
+// in a form eachKey cannot read
+func hasEach(data string) bool {
+	header, _, ok := splitFrontmatter(data)
+	return ok && eachField.MatchString(header)
+}
+
+// listValue returns the items of the list config value key
+func listValue(config map[string]any, key string) ([]any, error) {
+	value, ok := config[key]
+	if !ok {
+		return nil, fmt.Errorf("missing config value for: %s", key)
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("config value for %s is not a list: %v", key, value)
+	}
+
+	items := make([]any, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// withItem returns a copy of config with item and index set
+func withItem(config map[string]any, item any, index int) map[string]any {
+	itemConfig := make(map[string]any, len(config)+2)
+	for k, v := range config {
+		itemConfig[k] = v
+	}
+	itemConfig["item"] = item
+	itemConfig["index"] = index
+	return itemConfig
+}
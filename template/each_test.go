@@ -0,0 +1,141 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProcessor_Expand(t *testing.T) {
+	processor := New("/templates", "/output")
+	config := map[string]any{
+		"name":   "post",
+		"fields": []any{"ID", "Title"},
+		"dirs":   []any{"api", "", "web"},
+		"models": []any{map[string]any{"name": "Post"}},
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "single file",
+			path: "[name].go.tpl",
+			want: []string{"/output/post.go"},
+		},
+		{
+			name: "one file per item",
+			path: "[name]/[fields...]_validator.go.tpl",
+			want: []string{"/output/post/ID_validator.go", "/output/post/Title_validator.go"},
+		},
+		{
+			name: "items that empty a segment are skipped",
+			path: "[dirs...]/x.go",
+			want: []string{"/output/api/x.go", "/output/web/x.go"},
+		},
+		{
+			name:    "items must be scalars",
+			path:    "[models...].go.tpl",
+			wantErr: true,
+		},
+		{
+			name:    "not a list",
+			path:    "[name...].go.tpl",
+			wantErr: true,
+		},
+		{
+			name:    "two lists",
+			path:    "[fields...]/[fields...].go.tpl",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs, err := processor.Expand(tt.path, config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, out := range outputs {
+				got = append(got, out.Target)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expand() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expand() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	// Items are available to the template
+	outputs, err := processor.Expand("[fields...].go.tpl", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs[1].Config["item"] != "Title" || outputs[1].Config["index"] != 1 {
+		t.Errorf("Expand() config = %v, want item Title at index 1", outputs[1].Config)
+	}
+}
+
+func TestProcessor_ProcessFileEach(t *testing.T) {
+	tmpDir := t.TempDir()
+	templatePath := filepath.Join(tmpDir, "handler.go.tpl")
+
+	tmpl := "---\neach: endpoints\nto: handlers/{{ .item.name | snake }}.txt\n---\n{{ .index }}: {{ .item.method }} {{ .item.path }}\n"
+	if err := os.WriteFile(templatePath, []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	processor := New(tmpDir, tmpDir)
+	config := map[string]any{
+		"endpoints": []any{
+			map[string]any{"name": "ListPosts", "method": "GET", "path": "/posts"},
+			map[string]any{"name": "CreatePost", "method": "POST", "path": "/posts"},
+		},
+	}
+	if err := processor.ProcessFile(templatePath, filepath.Join(tmpDir, "handler.go"), config); err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+
+	for file, want := range map[string]string{
+		"list_posts.txt":  "0: GET /posts\n",
+		"create_post.txt": "1: POST /posts\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(tmpDir, "handlers", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != want {
+			t.Errorf("%s = %q, want %q", file, content, want)
+		}
+	}
+
+	// each without a target per item is rejected
+	if err := os.WriteFile(templatePath, []byte("---\neach: endpoints\n---\nx\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.ProcessFile(templatePath, filepath.Join(tmpDir, "handler.go"), config); err == nil {
+		t.Error("ProcessFile() expected error for each without to")
+	}
+
+	// each is not rendered, so a templated list name is rejected rather than
+	// rendering the template once without an item
+	tmpl = "---\neach: {{ .listName }}\nto: handlers/{{ .item.name | snake }}.txt\n---\n{{ .item.path }}\n"
+	if err := os.WriteFile(templatePath, []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config["listName"] = "endpoints"
+	err := processor.ProcessFile(templatePath, filepath.Join(tmpDir, "handler.go"), config)
+	if err == nil || !strings.Contains(err.Error(), "not a template") {
+		t.Errorf("ProcessFile() error = %v, want error for a templated each", err)
+	}
+}
@@ -0,0 +1,162 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Frontmatter is per-file metadata given in a YAML block at the top of a
+// .tpl file, between two "---" lines. The block is rendered like the rest
+// of the template, with the generator config, before it is parsed.
+type Frontmatter struct {
+	// To replaces the target path, relative to the output directory. Like
+	// template paths it may use [key] placeholders.
+	To string `yaml:"to"`
+
+	// SkipIf skips the file when it renders to true, for example
+	// skipIf: {{ not .withTests }}
+	SkipIf bool `yaml:"skipIf"`
+
+	// Overwrite replaces an existing file when true and never touches it
+	// when false, regardless of -force and -skip-existing. When unset the
+	// run's conflict policy applies.
+	Overwrite *bool `yaml:"overwrite"`
+
+	// Each names a list in the config. The template is rendered once per
+	// item, with the item in .item and its position in .index, so it needs
+	// a target that depends on the item or inject.
+	Each string `yaml:"each"`
+
+	// Mode is the octal file mode of the target, such as "0600".
+	// Executable adds the execute bits to it.
+	Mode       string `yaml:"mode"`
+	Executable bool   `yaml:"executable"`
+
+	// Inject inserts the rendered template into the existing target file
+	// instead of writing the whole file. Exactly one of the position fields
+	// says where it goes.
+	Inject  bool   `yaml:"inject"`
+	Before  string `yaml:"before"`
+	After   string `yaml:"after"`
+	Anchor  string `yaml:"anchor"`
+	Prepend bool   `yaml:"prepend"`
+	Append  bool   `yaml:"append"`
+}
+
+const frontmatterDelim = "---"
+
+// parseFrontmatter splits a template into its rendered frontmatter and body.
+// Templates without frontmatter are returned unchanged.
+func (p *Processor) parseFrontmatter(data string, config map[string]any) (Frontmatter, string, error) {
+	var fm Frontmatter
+
+	header, body, ok := splitFrontmatter(data)
+	if !ok {
+		return fm, data, nil
+	}
+
+	rendered, err := p.renderFileText(header, config)
+	if err != nil {
+		return fm, "", fmt.Errorf("frontmatter: %w", err)
+	}
+
+	if err := yaml.UnmarshalStrict([]byte(rendered), &fm); err != nil {
+		return fm, "", fmt.Errorf("error parsing frontmatter: %w", err)
+	}
+
+	if err := fm.validate(); err != nil {
+		return fm, "", fmt.Errorf("invalid frontmatter: %w", err)
+	}
+
+	return fm, body, nil
+}
+
+func splitFrontmatter(data string) (header, body string, ok bool) {
+	rest, found := strings.CutPrefix(data, frontmatterDelim+"\n")
+	if !found {
+		return "", data, false
+	}
+
+	if strings.HasPrefix(rest, frontmatterDelim) {
+		header, body = "", rest
+	} else {
+		end := strings.Index(rest, "\n"+frontmatterDelim)
+		if end == -1 {
+			return "", data, false
+		}
+		header, body = rest[:end+1], rest[end+1:]
+	}
+
+	body = strings.TrimPrefix(body, frontmatterDelim)
+	if !strings.HasPrefix(body, "\n") && body != "" {
+		return "", data, false
+	}
+	return header, strings.TrimPrefix(body, "\n"), true
+}
+
+// FileMode returns the mode the target file should have, or zero to keep
+// the default
+func (fm Frontmatter) FileMode() (os.FileMode, error) {
+	var mode os.FileMode
+	if fm.Mode != "" {
+		m, err := strconv.ParseUint(fm.Mode, 8, 32)
+		if err != nil || m > 0o777 {
+			return 0, fmt.Errorf("invalid mode %q, expected octal permissions such as 0644", fm.Mode)
+		}
+		mode = os.FileMode(m)
+	}
+
+	if fm.Executable {
+		if mode == 0 {
+			mode = 0o644
+		}
+		mode |= 0o111
+	}
+
+	return mode, nil
+}
+
+func (fm Frontmatter) validate() error {
+	if _, err := fm.FileMode(); err != nil {
+		return err
+	}
+
+	var positions []string
+	for name, set := range map[string]bool{
+		"before":  fm.Before != "",
+		"after":   fm.After != "",
+		"anchor":  fm.Anchor != "",
+		"prepend": fm.Prepend,
+		"append":  fm.Append,
+	} {
+		if set {
+			positions = append(positions, name)
+		}
+	}
+
+	sort.Strings(positions)
+
+	if fm.Each != "" && fm.To == "" && !fm.Inject {
+		return fmt.Errorf("each requires to or inject")
+	}
+
+	if !fm.Inject {
+		if len(positions) > 0 {
+			return fmt.Errorf("%s requires inject: true", strings.Join(positions, ", "))
+		}
+		return nil
+	}
+
+	if fm.Overwrite != nil {
+		return fmt.Errorf("overwrite cannot be used with inject")
+	}
+	if len(positions) != 1 {
+		return fmt.Errorf("inject needs exactly one of before, after, anchor, prepend or append")
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package template
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestProcessor_ParseFrontmatter(t *testing.T) {
+	processor := New("/templates", "/output")
+	config := map[string]any{"path": "/posts", "withTests": false}
+	overwrite := false
+
+	tests := []struct {
+		name     string
+		data     string
+		want     Frontmatter
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "no frontmatter",
+			data:     "package web\n",
+			wantBody: "package web\n",
+		},
+		{
+			name:     "inject after",
+			data:     "---\ninject: true\nafter: e := echo.New\\(\\)\n---\ne.GET(\"{{ .path }}\")\n",
+			want:     Frontmatter{Inject: true, After: `e := echo.New\(\)`},
+			wantBody: "e.GET(\"{{ .path }}\")\n",
+		},
+		{
+			name:     "rendered with config",
+			data:     "---\ninject: true\nbefore: '{{ .path }}'\n---\nx\n",
+			want:     Frontmatter{Inject: true, Before: "/posts"},
+			wantBody: "x\n",
+		},
+		{
+			name:     "empty frontmatter",
+			data:     "---\n---\n---\napiVersion: v1\n",
+			wantBody: "---\napiVersion: v1\n",
+		},
+		{
+			name:     "file settings",
+			data:     "---\nto: cmd/[name].sh\nskipIf: {{ not .withTests }}\noverwrite: false\nmode: 0600\nexecutable: true\n---\nx\n",
+			want:     Frontmatter{To: "cmd/[name].sh", SkipIf: true, Overwrite: &overwrite, Mode: "0600", Executable: true},
+			wantBody: "x\n",
+		},
+		{
+			name:    "invalid mode",
+			data:    "---\nmode: rwx\n---\nx\n",
+			wantErr: true,
+		},
+		{
+			name:    "overwrite with inject",
+			data:    "---\ninject: true\nappend: true\noverwrite: true\n---\nx\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			data:    "---\ninjekt: true\n---\nx\n",
+			wantErr: true,
+		},
+		{
+			name:    "position without inject",
+			data:    "---\nafter: x\n---\nx\n",
+			wantErr: true,
+		},
+		{
+			name:    "inject without position",
+			data:    "---\ninject: true\n---\nx\n",
+			wantErr: true,
+		},
+		{
+			name:    "inject with two positions",
+			data:    "---\ninject: true\nprepend: true\nappend: true\n---\nx\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, body, err := processor.parseFrontmatter(tt.data, config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFrontmatter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFrontmatter() = %+v, want %+v", got, tt.want)
+			}
+			if body != tt.wantBody {
+				t.Errorf("parseFrontmatter() body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestFrontmatter_FileMode(t *testing.T) {
+	tests := []struct {
+		fm   Frontmatter
+		want os.FileMode
+	}{
+		{fm: Frontmatter{}, want: 0},
+		{fm: Frontmatter{Mode: "0600"}, want: 0o600},
+		{fm: Frontmatter{Executable: true}, want: 0o755},
+		{fm: Frontmatter{Mode: "0700", Executable: true}, want: 0o711 | 0o700},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.fm.FileMode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("FileMode(%+v) = %o, want %o", tt.fm, got, tt.want)
+		}
+	}
+}
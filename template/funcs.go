@@ -0,0 +1,319 @@
+package template
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Funcs returns the helper functions available in templates and post
+// commands. Functions that take a value to transform take it as their last
+// argument, so they can be used in pipelines: {{ .name | snake }}.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"camel":     camelCase,
+		"pascal":    pascalCase,
+		"snake":     snakeCase,
+		"kebab":     kebabCase,
+		"screaming": screamingCase,
+		"plural":    plural,
+		"singular":  singular,
+		"indent":    indent,
+		"quote":     strconv.Quote,
+		"join":      join,
+		"split":     split,
+		"default":   defaultValue,
+		"goIdent":   goIdent,
+	}
+}
+
+// words splits s into words on separators, lower to upper case changes and
+// the end of acronyms, so "HTTPServer_config-id" becomes HTTP Server config id
+func words(s string) []string {
+	var result []string
+	var current []rune
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(current) > 0 {
+				result = append(result, string(current))
+				current = nil
+			}
+			continue
+		}
+
+		if len(current) > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				result = append(result, string(current))
+				current = nil
+			}
+		}
+
+		current = append(current, r)
+	}
+
+	if len(current) > 0 {
+		result = append(result, string(current))
+	}
+	return result
+}
+
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func camelCase(s string) string {
+	var out strings.Builder
+	for i, word := range words(s) {
+		if i == 0 {
+			out.WriteString(strings.ToLower(word))
+		} else {
+			out.WriteString(capitalize(word))
+		}
+	}
+	return out.String()
+}
+
+func pascalCase(s string) string {
+	var out strings.Builder
+	for _, word := range words(s) {
+		out.WriteString(capitalize(word))
+	}
+	return out.String()
+}
+
+func snakeCase(s string) string {
+	return strings.ToLower(strings.Join(words(s), "_"))
+}
+
+func kebabCase(s string) string {
+	return strings.ToLower(strings.Join(words(s), "-"))
+}
+
+func screamingCase(s string) string {
+	return strings.ToUpper(strings.Join(words(s), "_"))
+}
+
+// irregularPlurals holds words whose plural the suffix rules in plural and
+// singular get wrong, in either direction
+var irregularPlurals = map[string]string{
+	"child":  "children",
+	"person": "people",
+	"man":    "men",
+	"woman":  "women",
+	"mouse":  "mice",
+	"goose":  "geese",
+	"foot":   "feet",
+	"tooth":  "teeth",
+
+	// -f and -fe words ending in -ves, as most others such as roof and safe
+	// only add an s, and archives or drives are not plurals of -f words
+	"calf":  "calves",
+	"elf":   "elves",
+	"half":  "halves",
+	"knife": "knives",
+	"leaf":  "leaves",
+	"life":  "lives",
+	"loaf":  "loaves",
+	"self":  "selves",
+	"shelf": "shelves",
+	"thief": "thieves",
+	"wife":  "wives",
+	"wolf":  "wolves",
+
+	// -ie words, as singular turns -ies into -y
+	"cookie":  "cookies",
+	"movie":   "movies",
+	"pie":     "pies",
+	"rookie":  "rookies",
+	"tie":     "ties",
+	"zombie":  "zombies",
+	"calorie": "calories",
+
+	// -use words, as singular turns other -uses such as statuses into -us
+	"abuse":  "abuses",
+	"excuse": "excuses",
+	"fuse":   "fuses",
+	"muse":   "muses",
+	"refuse": "refuses",
+	"ruse":   "ruses",
+	"use":    "uses",
+
+	// -s words ending in -ses, as singular only removes the s from -ases
+	// such as cases and databases
+	"alias":     "aliases",
+	"atlas":     "atlases",
+	"bias":      "biases",
+	"canvas":    "canvases",
+	"gas":       "gases",
+	"analysis":  "analyses",
+	"crisis":    "crises",
+	"diagnosis": "diagnoses",
+	"thesis":    "theses",
+
+	"hero":   "heroes",
+	"potato": "potatoes",
+	"tomato": "tomatoes",
+	"quiz":   "quizzes",
+}
+
+var uncountable = map[string]bool{
+	"data":        true,
+	"equipment":   true,
+	"information": true,
+	"metadata":    true,
+	"news":        true,
+	"series":      true,
+	"species":     true,
+}
+
+// plural returns the English plural of a word, keeping the case of its
+// first letter
+func plural(s string) string {
+	lower := strings.ToLower(s)
+	if s == "" || uncountable[lower] {
+		return s
+	}
+	if p, ok := irregularPlurals[lower]; ok {
+		return matchCase(s, p)
+	}
+
+	switch {
+	case hasAnySuffix(lower, "s", "x", "z", "ch", "sh"):
+		return s + "es"
+	case strings.HasSuffix(lower, "y") && !endsWithVowelY(lower):
+		return s[:len(s)-1] + "ies"
+	}
+	return s + "s"
+}
+
+// singular returns the English singular of a word, keeping the case of its
+// first letter
+func singular(s string) string {
+	lower := strings.ToLower(s)
+	if s == "" || uncountable[lower] {
+		return s
+	}
+	for single, p := range irregularPlurals {
+		if lower == p {
+			return matchCase(s, single)
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(lower) > 3:
+		return s[:len(s)-3] + "y"
+	case hasAnySuffix(lower, "sses", "xes", "zzes", "tzes", "ches", "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "uses") && !hasAnySuffix(lower, "auses", "ouses"):
+		// statuses and buses, but not causes and houses
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "ss"):
+		return s
+	case strings.HasSuffix(lower, "s"):
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+func hasAnySuffix(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func endsWithVowelY(s string) bool {
+	return len(s) > 1 && strings.ContainsRune("aeiou", rune(s[len(s)-2]))
+}
+
+func matchCase(original, word string) string {
+	if unicode.IsUpper([]rune(original)[0]) {
+		return capitalize(word)
+	}
+	return word
+}
+
+// indent prefixes every non-empty line of s with n spaces
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// join joins the items of a list, formatting each with fmt.Sprint
+func join(sep string, list any) (string, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: expected a list, got %T", list)
+	}
+
+	items := make([]string, v.Len())
+	for i := range items {
+		items[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(items, sep), nil
+}
+
+func split(sep string, s string) []string {
+	return strings.Split(s, sep)
+}
+
+// defaultValue returns value, or def if value is empty
+func defaultValue(def any, value any) any {
+	if value == nil {
+		return def
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if v.Len() == 0 {
+			return def
+		}
+	}
+	return value
+}
+
+// goIdent turns s into a valid Go identifier by replacing invalid characters
+// with underscores, prefixing a leading digit and suffixing keywords
+func goIdent(s string) string {
+	var out strings.Builder
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+			out.WriteRune(r)
+		case unicode.IsDigit(r):
+			if i == 0 {
+				out.WriteRune('_')
+			}
+			out.WriteRune(r)
+		default:
+			out.WriteRune('_')
+		}
+	}
+
+	ident := out.String()
+	if ident == "" {
+		return "_"
+	}
+	if token.IsKeyword(ident) {
+		ident += "_"
+	}
+	return ident
+}
@@ -0,0 +1,122 @@
+package template
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestFuncs(t *testing.T) {
+	config := map[string]any{
+		"name":   "HTTPServer_config-id",
+		"model":  "category",
+		"fields": []any{"id", "title", 3},
+		"empty":  "",
+		"body":   "a\n\nb",
+	}
+
+	tests := []struct {
+		tpl  string
+		want string
+	}{
+		{`{{ .name | camel }}`, "httpServerConfigId"},
+		{`{{ .name | pascal }}`, "HttpServerConfigId"},
+		{`{{ .name | snake }}`, "http_server_config_id"},
+		{`{{ .name | kebab }}`, "http-server-config-id"},
+		{`{{ .name | screaming }}`, "HTTP_SERVER_CONFIG_ID"},
+		{`{{ "postsEdit" | pascal }}`, "PostsEdit"},
+		{`{{ "v2Api" | snake }}`, "v2_api"},
+		{`{{ .model | plural }}`, "categories"},
+		{`{{ "Person" | plural }}`, "People"},
+		{`{{ "box" | plural }}`, "boxes"},
+		{`{{ "day" | plural }}`, "days"},
+		{`{{ "knife" | plural }}`, "knives"},
+		{`{{ "categories" | singular }}`, "category"},
+		{`{{ "children" | singular }}`, "child"},
+		{`{{ "addresses" | singular }}`, "address"},
+		{`{{ "posts" | singular }}`, "post"},
+		{`{{ "class" | singular }}`, "class"},
+		{`{{ .body | indent 2 }}`, "  a\n\n  b"},
+		{`{{ .model | quote }}`, `"category"`},
+		{`{{ .fields | join ", " }}`, "id, title, 3"},
+		{`{{ range "a/b" | split "/" }}[{{.}}]{{end}}`, "[a][b]"},
+		{`{{ .empty | default "fallback" }}`, "fallback"},
+		{`{{ .missing | default "fallback" }}`, "fallback"},
+		{`{{ .model | default "fallback" }}`, "category"},
+		{`{{ "2fa-code" | goIdent }}`, "_2fa_code"},
+		{`{{ "type" | goIdent }}`, "type_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tpl, func(t *testing.T) {
+			tmpl, err := template.New("test").Funcs(Funcs()).Parse(tt.tpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out strings.Builder
+			if err := tmpl.Execute(&out, config); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("%s = %q, want %q", tt.tpl, out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPluralSingular(t *testing.T) {
+	tests := []struct {
+		single string
+		plural string
+	}{
+		{"post", "posts"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"box", "boxes"},
+		{"address", "addresses"},
+		{"branch", "branches"},
+		{"size", "sizes"},
+		{"buzz", "buzzes"},
+		{"quiz", "quizzes"},
+		{"status", "statuses"},
+		{"bus", "buses"},
+		{"campus", "campuses"},
+		{"menu", "menus"},
+		{"house", "houses"},
+		{"cause", "causes"},
+		{"use", "uses"},
+		{"excuse", "excuses"},
+		{"case", "cases"},
+		{"database", "databases"},
+		{"alias", "aliases"},
+		{"analysis", "analyses"},
+		{"archive", "archives"},
+		{"drive", "drives"},
+		{"move", "moves"},
+		{"curve", "curves"},
+		{"movie", "movies"},
+		{"cookie", "cookies"},
+		{"knife", "knives"},
+		{"wife", "wives"},
+		{"shelf", "shelves"},
+		{"leaf", "leaves"},
+		{"roof", "roofs"},
+		{"safe", "safes"},
+		{"person", "people"},
+		{"Child", "Children"},
+		{"hero", "heroes"},
+		{"data", "data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.single, func(t *testing.T) {
+			if got := plural(tt.single); got != tt.plural {
+				t.Errorf("plural(%q) = %q, want %q", tt.single, got, tt.plural)
+			}
+			if got := singular(tt.plural); got != tt.single {
+				t.Errorf("singular(%q) = %q, want %q", tt.plural, got, tt.single)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.quinn.io/g/fileops"
+)
+
+// inject inserts a rendered snippet into the existing file at targetPath.
+// Nothing changes if the file already contains the snippet.
+func (p *Processor) inject(targetPath, snippet string, fm Frontmatter) error {
+	existing, err := fileops.ReadFile(targetPath)
+	if err != nil {
+		return fmt.Errorf("error reading inject target: %w", err)
+	}
+
+	if strings.TrimSpace(snippet) == "" || injected(existing, snippet) {
+		fileops.Print("Already injected into %s\n", targetPath)
+		return nil
+	}
+
+	result, err := injectAt(existing, snippet, fm)
+	if err != nil {
+		return fmt.Errorf("error injecting into %s: %w", targetPath, err)
+	}
+
+	result, err = fileops.FormatGo(targetPath, result)
+	if err != nil {
+		return err
+	}
+
+	if err := fileops.WriteFile(targetPath, result); err != nil {
+		return fmt.Errorf("error writing target file: %w", err)
+	}
+
+	return nil
+}
+
+// injected reports whether src already contains snippet as whole,
+// consecutive lines. The whitespace around each line is ignored, as
+// formatting the file after an injection may re-indent the snippet, such as
+// Go code indented with spaces.
+func injected(src, snippet string) bool {
+	lines := trimLines(src)
+	want := trimLines(strings.TrimSpace(snippet))
+	for i := 0; i+len(want) <= len(lines); i++ {
+		if slices.Equal(lines[i:i+len(want)], want) {
+			return true
+		}
+	}
+	return false
+}
+
+func trimLines(s string) []string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return lines
+}
+
+// injectAt inserts snippet as whole lines into src. Before and after insert
+// next to the first line matching their regular expression, and a named
+// anchor inserts before the first line containing "g:<name>", so repeated
+// injections stay in order.
+func injectAt(src, snippet string, fm Frontmatter) (string, error) {
+	snippet = strings.TrimRight(snippet, "\n") + "\n"
+
+	switch {
+	case fm.Prepend:
+		return snippet + src, nil
+	case fm.Append:
+		if src != "" && !strings.HasSuffix(src, "\n") {
+			src += "\n"
+		}
+		return src + snippet, nil
+	}
+
+	pattern, after := fm.Before, false
+	switch {
+	case fm.After != "":
+		pattern, after = fm.After, true
+	case fm.Anchor != "":
+		pattern = `g:` + regexp.QuoteMeta(fm.Anchor) + `\b`
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	offset := 0
+	for _, line := range strings.SplitAfter(src, "\n") {
+		if re.MatchString(strings.TrimSuffix(line, "\n")) {
+			at := offset
+			if after {
+				at += len(line)
+				if !strings.HasSuffix(line, "\n") {
+					snippet = "\n" + snippet
+				}
+			}
+			return src[:at] + snippet + src[at:], nil
+		}
+		offset += len(line)
+	}
+
+	return "", fmt.Errorf("no line matches %q", pattern)
+}
@@ -0,0 +1,139 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const routes = `routes:
+  - /
+  # g:routes
+end
+`
+
+func TestInjectAt(t *testing.T) {
+	tests := []struct {
+		name    string
+		fm      Frontmatter
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "before",
+			fm:   Frontmatter{Inject: true, Before: "^end"},
+			want: "routes:\n  - /\n  # g:routes\n  - /new\nend\n",
+		},
+		{
+			name: "after",
+			fm:   Frontmatter{Inject: true, After: "^routes:"},
+			want: "routes:\n  - /new\n  - /\n  # g:routes\nend\n",
+		},
+		{
+			name: "anchor",
+			fm:   Frontmatter{Inject: true, Anchor: "routes"},
+			want: "routes:\n  - /\n  - /new\n  # g:routes\nend\n",
+		},
+		{
+			name: "prepend",
+			fm:   Frontmatter{Inject: true, Prepend: true},
+			want: "  - /new\n" + routes,
+		},
+		{
+			name: "append",
+			fm:   Frontmatter{Inject: true, Append: true},
+			want: routes + "  - /new\n",
+		},
+		{
+			name:    "no match",
+			fm:      Frontmatter{Inject: true, Anchor: "missing"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			fm:      Frontmatter{Inject: true, After: "("},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := injectAt(routes, "  - /new", tt.fm)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("injectAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("injectAt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInjected(t *testing.T) {
+	src := "func routes(e *echo.Echo) {\n\te.GET(\"/\", home)\n\tif debug {\n\t\te.GET(\"/debug\", debug)\n\t}\n}\n"
+
+	tests := []struct {
+		snippet string
+		want    bool
+	}{
+		{"\te.GET(\"/\", home)\n", true},
+		{"  if debug {\n    e.GET(\"/debug\", debug)\n  }\n", true},
+		{"e.GET(\"/debug\", debug)", true},
+		{"e.GET(\"/debug\"", false},
+		{"debug)", false},
+		{"e.GET(\"/posts\", posts)\n", false},
+		{"if debug {\n}\n", false},
+	}
+
+	for _, tt := range tests {
+		if got := injected(src, tt.snippet); got != tt.want {
+			t.Errorf("injected(%q) = %v, want %v", tt.snippet, got, tt.want)
+		}
+	}
+
+	// Lines that only start or end with the snippet are not matches
+	for _, tt := range []struct{ src, snippet string }{
+		{"apps:\n  - webapp\n", "  - web\n"},
+		{"func routes() {\n\tr.AdminRoutes()\n}\n", "Routes()\n"},
+	} {
+		if injected(tt.src, tt.snippet) {
+			t.Errorf("injected(%q, %q) = true, want false", tt.src, tt.snippet)
+		}
+	}
+}
+
+func TestProcessor_ProcessFileInject(t *testing.T) {
+	tmpDir := t.TempDir()
+	templatePath := filepath.Join(tmpDir, "routes.txt.tpl")
+	targetPath := filepath.Join(tmpDir, "routes.txt")
+
+	tmpl := "---\ninject: true\nanchor: routes\n---\n  - {{ .path }}\n"
+	if err := os.WriteFile(templatePath, []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(targetPath, []byte(routes), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Injecting twice adds the snippet once
+	processor := New(tmpDir, tmpDir)
+	for i := 0; i < 2; i++ {
+		if err := processor.ProcessFile(templatePath, targetPath, map[string]any{"path": "/posts"}); err != nil {
+			t.Fatalf("ProcessFile() error = %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(targetPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "routes:\n  - /\n  - /posts\n  # g:routes\nend\n"
+	if string(content) != want {
+		t.Errorf("ProcessFile() = %q, want %q", content, want)
+	}
+
+	// The target must exist
+	if err := processor.ProcessFile(templatePath, filepath.Join(tmpDir, "missing.txt"), map[string]any{"path": "/posts"}); err == nil {
+		t.Error("ProcessFile() expected error for missing inject target")
+	}
+}
@@ -1,8 +1,13 @@
 package template
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"os"
 	"path"
+	"regexp"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -13,8 +18,20 @@ import (
 type Processor struct {
 	templateDir string
 	outDir      string
+
+	// Conflict decides what to do when a target file already exists with
+	// different content
+	Conflict ConflictPolicy
+	stdin    *bufio.Reader
+	funcs    template.FuncMap
+	delims   [2]string
+	engine   Engine
+	partials map[string]string
 }
 
+// Engine renders the text of a template file in place of text/template
+type Engine func(text string, config map[string]any) (string, error)
+
 // New creates a new template processor
 func New(templateDir, outDir string) *Processor {
 	return &Processor{
@@ -23,8 +40,155 @@ func New(templateDir, outDir string) *Processor {
 	}
 }
 
-// ProcessPath processes a template path, replacing placeholders with config values
-func (p *Processor) ProcessPath(templatePath string, config map[string]string) (string, error) {
+// AddFuncs makes additional functions available to templates, replacing
+// built-in functions with the same name
+func (p *Processor) AddFuncs(funcs template.FuncMap) {
+	if p.funcs == nil {
+		p.funcs = template.FuncMap{}
+	}
+	for name, fn := range funcs {
+		p.funcs[name] = fn
+	}
+}
+
+// SetDelims sets the action delimiters of template files, such as "[["
+// and "]]", for files that use {{ }} themselves. Empty delimiters restore
+// the defaults.
+func (p *Processor) SetDelims(left, right string) {
+	p.delims = [2]string{left, right}
+}
+
+// AddPartials makes named templates available to template files, which
+// can include them with {{ template "name" . }}
+func (p *Processor) AddPartials(partials map[string]string) {
+	if p.partials == nil {
+		p.partials = map[string]string{}
+	}
+	for name, text := range partials {
+		p.partials[name] = text
+	}
+}
+
+// SetEngine renders template files and their frontmatter with engine
+// instead of text/template
+func (p *Processor) SetEngine(engine Engine) {
+	p.engine = engine
+}
+
+// renderFileText renders the text of a template file with the processor's
+// engine or delimiters. Partials are shared by generators with different
+// delimiters, so they always use the default ones.
+func (p *Processor) renderFileText(text string, config map[string]any) (string, error) {
+	if p.engine != nil {
+		if name := p.includedPartial(text); name != "" {
+			return "", fmt.Errorf("partial %s cannot be included by the js engine", name)
+		}
+		result, err := p.engine(text, config)
+		if err != nil {
+			return "", fmt.Errorf("error executing template: %w", err)
+		}
+		return result, nil
+	}
+
+	tmpl := template.New("file").Delims(p.delims[0], p.delims[1]).Funcs(Funcs()).Funcs(p.funcs)
+
+	names := make([]string, 0, len(p.partials))
+	for name := range p.partials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	partials := template.New("partials").Funcs(Funcs()).Funcs(p.funcs)
+	for _, name := range names {
+		if _, err := partials.New(name).Parse(p.partials[name]); err != nil {
+			return "", fmt.Errorf("error parsing partial %s: %w", name, err)
+		}
+	}
+	for _, partial := range partials.Templates() {
+		if partial.Tree == nil {
+			continue
+		}
+		if _, err := tmpl.AddParseTree(partial.Name(), partial.Tree); err != nil {
+			return "", fmt.Errorf("error adding partial %s: %w", partial.Name(), err)
+		}
+	}
+
+	if _, err := tmpl.Parse(text); err != nil {
+		return "", fmt.Errorf("error parsing template file: %w", err)
+	}
+
+	var result strings.Builder
+	if err := tmpl.Execute(&result, config); err != nil {
+		return "", fmt.Errorf("error executing template: %w", err)
+	}
+	return result.String(), nil
+}
+
+var partialAction = regexp.MustCompile(`\{\{-?\s*(?:template|block)\s+"([^"]+)"`)
+
+// includedPartial returns the name of a partial that text includes with
+// {{ template }}, which only text/template can render
+func (p *Processor) includedPartial(text string) string {
+	for _, match := range partialAction.FindAllStringSubmatch(text, -1) {
+		if _, ok := p.partials[match[1]]; ok {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// Render executes a template string, such as a post command, with the
+// same functions available to template files
+func (p *Processor) Render(name, text string, config map[string]any) (string, error) {
+	tmpl, err := template.New(name).Funcs(Funcs()).Funcs(p.funcs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s template: %w", name, err)
+	}
+
+	var result strings.Builder
+	if err := tmpl.Execute(&result, config); err != nil {
+		return "", fmt.Errorf("error executing %s template: %w", name, err)
+	}
+	return result.String(), nil
+}
+
+// ErrSkipPath is returned by ProcessPath when a path segment made of
+// placeholders is empty, meaning the file should not be rendered
+var ErrSkipPath = errors.New("path segment is empty")
+
+// ProcessPath processes a template path, replacing placeholders with config
+// values. If a segment of the path is empty after substitution, such as
+// [migrationDir] with an empty value, ErrSkipPath is returned.
+func (p *Processor) ProcessPath(templatePath string, config map[string]any) (string, error) {
+	targetPath, err := substitutePath(templatePath, config)
+	if err != nil {
+		return "", err
+	}
+
+	for _, segment := range strings.Split(templatePath, "/") {
+		substituted, err := substitutePath(segment, config)
+		if err == nil && segment != "" && strings.TrimSuffix(substituted, ".tpl") == "" {
+			return "", fmt.Errorf("%s: %w", templatePath, ErrSkipPath)
+		}
+	}
+
+	targetPath = path.Join(p.outDir, targetPath)
+	targetPath = strings.TrimSuffix(targetPath, ".tpl")
+	return targetPath, nil
+}
+
+// RenderPath resolves a path written in g.yaml, such as a transform target.
+// Both [key] placeholders and template syntax are replaced with config
+// values. The path stays relative to the output directory.
+func (p *Processor) RenderPath(name, text string, config map[string]any) (string, error) {
+	substituted, err := substitutePath(text, config)
+	if err != nil {
+		return "", err
+	}
+	return p.Render(name, substituted, config)
+}
+
+// substitutePath replaces [key] placeholders in a path with config values
+func substitutePath(templatePath string, config map[string]any) (string, error) {
 	var argName string
 	var brackets bool
 	var targetPath string
@@ -45,7 +209,12 @@ func (p *Processor) ProcessPath(templatePath string, config map[string]string) (
 			if !ok {
 				return "", fmt.Errorf("missing config value for: %s", argName)
 			}
-			targetPath += val
+			switch val.(type) {
+			case string, bool, int, int64, float64:
+				targetPath += fmt.Sprint(val)
+			default:
+				return "", fmt.Errorf("config value for %s cannot be used in a path: %v", argName, val)
+			}
 			argName = ""
 		default:
 			if brackets {
@@ -60,44 +229,139 @@ func (p *Processor) ProcessPath(templatePath string, config map[string]string) (
 		return "", fmt.Errorf("unterminated open bracket: %s", templatePath)
 	}
 
-	targetPath = path.Join(p.outDir, targetPath)
-	targetPath = strings.TrimSuffix(targetPath, ".tpl")
 	return targetPath, nil
 }
 
 // ProcessFile processes a template file with the given configuration
-func (p *Processor) ProcessFile(sourcePath, targetPath string, config map[string]string) error {
+func (p *Processor) ProcessFile(sourcePath, targetPath string, config map[string]any) error {
 	// Read the template file
 	tmplData, err := fileops.ReadFile(sourcePath)
 	if err != nil {
 		return fmt.Errorf("error reading template file: %w", err)
 	}
 
-	// Create the target directory if it does not exist
-	if err := fileops.MkdirP(targetPath); err != nil {
-		return fmt.Errorf("error creating target directory: %w", err)
+	// A template with each in its frontmatter is rendered once per item
+	if key := eachKey(sourcePath, tmplData); key != "" {
+		items, err := listValue(config, key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sourcePath, err)
+		}
+		for i, item := range items {
+			if err := p.renderFile(sourcePath, tmplData, targetPath, withItem(config, item, i)); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
+	return p.renderFile(sourcePath, tmplData, targetPath, config)
+}
+
+// renderFile renders template data read from sourcePath to targetPath
+func (p *Processor) renderFile(sourcePath, tmplData, targetPath string, config map[string]any) error {
+	var err error
+	var fm Frontmatter
+	// Templates are rendered, other files and binary files are copied as is
+	render := strings.HasSuffix(sourcePath, ".tpl") && !fileops.IsBinary(tmplData)
+
 	var result strings.Builder
-	if strings.HasSuffix(sourcePath, ".tpl") {
-		// Create and execute the template
-		tmpl, err := template.New("file").Parse(tmplData)
+	if render {
+		// each is read before the frontmatter is rendered, so one that is
+		// only set once rendered would silently render a single file
+		each := eachKey(sourcePath, tmplData)
+		if each == "" && hasEach(tmplData) {
+			return fmt.Errorf("%s: %w", sourcePath, errTemplatedEach)
+		}
+
+		// Split off the frontmatter, if any
+		fm, tmplData, err = p.parseFrontmatter(tmplData, config)
 		if err != nil {
-			return fmt.Errorf("error parsing template file: %w", err)
+			return fmt.Errorf("%s: %w", sourcePath, err)
+		}
+		if fm.Each != "" && each == "" {
+			return fmt.Errorf("%s: %w", sourcePath, errTemplatedEach)
 		}
 
-		// Execute the template to a string builder
-		if err := tmpl.Execute(&result, config); err != nil {
-			return fmt.Errorf("error executing template: %w", err)
+		// Execute the template
+		rendered, err := p.renderFileText(tmplData, config)
+		if err != nil {
+			return err
 		}
+		result.WriteString(rendered)
 	} else {
 		result.WriteString(tmplData)
 	}
 
+	if fm.SkipIf {
+		fileops.Print("Skipping %s\n", targetPath)
+		return nil
+	}
+
+	if fm.To != "" {
+		to, err := substitutePath(fm.To, config)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sourcePath, err)
+		}
+		targetPath = path.Join(p.outDir, to)
+	}
+
+	if fm.Inject {
+		return p.inject(targetPath, result.String(), fm)
+	}
+
+	// Create the target directory if it does not exist
+	if err := fileops.MkdirP(targetPath); err != nil {
+		return fmt.Errorf("error creating target directory: %w", err)
+	}
+
+	rendered := result.String()
+	if render {
+		rendered, err = fileops.FormatGo(targetPath, rendered)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check for an existing file that would be clobbered
+	if existing, err := fileops.ReadFile(targetPath); err == nil && existing != rendered {
+		conflict := p.Conflict
+		if fm.Overwrite != nil {
+			conflict = ConflictSkip
+			if *fm.Overwrite {
+				conflict = ConflictOverwrite
+			}
+		}
+
+		write, err := p.resolveConflict(conflict, targetPath, existing, rendered)
+		if err != nil {
+			return err
+		}
+		if !write {
+			return nil
+		}
+	}
+
 	// Write the result to the target file
-	if err := fileops.WriteFile(targetPath, result.String()); err != nil {
+	if err := fileops.WriteFile(targetPath, rendered); err != nil {
 		return fmt.Errorf("error writing target file: %w", err)
 	}
 
+	// The target gets the permissions of its source unless the frontmatter
+	// says otherwise
+	mode, err := fm.FileMode()
+	if err != nil {
+		return err
+	}
+	if mode == 0 {
+		if info, err := os.Stat(sourcePath); err == nil {
+			mode = info.Mode().Perm()
+		}
+	}
+	if mode != 0 {
+		if err := fileops.Chmod(targetPath, mode); err != nil {
+			return fmt.Errorf("error setting file mode: %w", err)
+		}
+	}
+
 	return nil
 }
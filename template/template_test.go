@@ -1,16 +1,20 @@
 package template
 
 import (
+	"bufio"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
 func TestProcessor_ProcessPath(t *testing.T) {
 	processor := New("/templates", "/output")
-	config := map[string]string{
-		"name": "test",
-		"type": "component",
+	config := map[string]any{
+		"name":  "test",
+		"type":  "component",
+		"empty": "",
 	}
 
 	tests := []struct {
@@ -30,6 +34,17 @@ func TestProcessor_ProcessPath(t *testing.T) {
 			path: "[type]/[name].txt",
 			want: "/output/component/test.txt",
 		},
+		{
+			name:        "empty segment",
+			path:        "[empty]/file.txt",
+			wantErr:     true,
+			wantErrText: "[empty]/file.txt: path segment is empty",
+		},
+		{
+			name: "empty value inside a segment",
+			path: "file[empty].txt",
+			want: "/output/file.txt",
+		},
 		{
 			name:        "unterminated bracket",
 			path:        "[type/[name].txt",
@@ -64,6 +79,54 @@ func TestProcessor_ProcessPath(t *testing.T) {
 	}
 }
 
+func TestProcessor_RenderPath(t *testing.T) {
+	processor := New("/templates", "/output")
+	config := map[string]any{
+		"name": "PostsShow",
+		"pkg":  "web",
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "placeholders",
+			path: "internal/[pkg]/[name].go",
+			want: "internal/web/PostsShow.go",
+		},
+		{
+			name: "template syntax",
+			path: "internal/{{ .pkg }}/{{ .name | snake }}.go",
+			want: "internal/web/posts_show.go",
+		},
+		{
+			name: "glob",
+			path: "internal/[pkg]/*.go",
+			want: "internal/web/*.go",
+		},
+		{
+			name:    "missing config value",
+			path:    "[missing].go",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := processor.RenderPath("file", tt.path, config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RenderPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestProcessor_ProcessFile(t *testing.T) {
 	// Create temporary directories for testing
 	tmpDir := t.TempDir()
@@ -82,7 +145,7 @@ func TestProcessor_ProcessFile(t *testing.T) {
 	}
 
 	processor := New(templateDir, outDir)
-	config := map[string]string{
+	config := map[string]any{
 		"name": "World",
 	}
 
@@ -102,3 +165,286 @@ func TestProcessor_ProcessFile(t *testing.T) {
 		t.Errorf("ProcessFile() output = %v, want %v", string(content), expected)
 	}
 }
+
+func TestProcessor_ProcessFileConflict(t *testing.T) {
+	tmpDir := t.TempDir()
+	templatePath := filepath.Join(tmpDir, "test.txt.tpl")
+	if err := os.WriteFile(templatePath, []byte("Hello {{.name}}!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := map[string]any{
+		"name": "World",
+	}
+
+	tests := []struct {
+		name     string
+		conflict ConflictPolicy
+		answers  string
+		want     string
+		wantOrig bool
+		wantErr  error
+	}{
+		{
+			name:     "overwrite",
+			conflict: ConflictOverwrite,
+			want:     "Hello World!",
+		},
+		{
+			name:     "skip",
+			conflict: ConflictSkip,
+			want:     "edited",
+		},
+		{
+			name:    "ask skip",
+			answers: "s\n",
+			want:    "edited",
+		},
+		{
+			name:     "ask diff then keep",
+			answers:  "d\nk\n",
+			want:     "Hello World!",
+			wantOrig: true,
+		},
+		{
+			name:    "ask no answer",
+			answers: "",
+			want:    "edited",
+			wantErr: ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetPath := filepath.Join(tmpDir, tt.name, "test.txt")
+			if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(targetPath, []byte("edited"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			processor := New(tmpDir, tmpDir)
+			processor.Conflict = tt.conflict
+			processor.stdin = bufio.NewReader(strings.NewReader(tt.answers))
+
+			err := processor.ProcessFile(templatePath, targetPath, config)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProcessFile() error = %v, want %v", err, tt.wantErr)
+			}
+
+			content, err := os.ReadFile(targetPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("ProcessFile() output = %v, want %v", string(content), tt.want)
+			}
+
+			orig, err := os.ReadFile(targetPath + ".orig")
+			if tt.wantOrig && string(orig) != "edited" {
+				t.Errorf("ProcessFile() .orig = %q, want edited", orig)
+			}
+			if !tt.wantOrig && err == nil {
+				t.Error("ProcessFile() wrote unexpected .orig file")
+			}
+		})
+	}
+}
+
+func TestProcessor_ProcessFileFrontmatter(t *testing.T) {
+	tmpDir := t.TempDir()
+	templateDir := filepath.Join(tmpDir, "templates")
+	outDir := filepath.Join(tmpDir, "output")
+
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	templates := map[string]string{
+		"script.sh.tpl":  "---\nto: bin/[name].sh\nexecutable: true\n---\necho {{ .name }}\n",
+		"test.txt.tpl":   "---\nskipIf: {{ not .withTests }}\n---\ntest\n",
+		"secret.txt.tpl": "---\nmode: \"0600\"\noverwrite: false\n---\nsecret\n",
+	}
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "secret.txt"), []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	processor := New(templateDir, outDir)
+	processor.Conflict = ConflictOverwrite
+	config := map[string]any{"name": "deploy", "withTests": false}
+
+	for name := range templates {
+		targetPath, err := processor.ProcessPath(name, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := processor.ProcessFile(filepath.Join(templateDir, name), targetPath, config); err != nil {
+			t.Fatalf("ProcessFile(%s) error = %v", name, err)
+		}
+	}
+
+	// The target path is overridden and the file is executable
+	info, err := os.Stat(filepath.Join(outDir, "bin", "deploy.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Errorf("script mode = %o, want 755", info.Mode().Perm())
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "script.sh")); !os.IsNotExist(err) {
+		t.Error("script rendered to its template path")
+	}
+
+	// The skipped file is not rendered
+	if _, err := os.Stat(filepath.Join(outDir, "test.txt")); !os.IsNotExist(err) {
+		t.Error("skipped file was rendered")
+	}
+
+	// overwrite: false wins over the run's conflict policy
+	content, err := os.ReadFile(filepath.Join(outDir, "secret.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "edited\n" {
+		t.Errorf("secret.txt = %q, want existing content", content)
+	}
+}
+
+func TestProcessor_ProcessFileModesAndBinary(t *testing.T) {
+	tmpDir := t.TempDir()
+	templateDir := filepath.Join(tmpDir, "templates")
+	outDir := filepath.Join(tmpDir, "output")
+
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	favicon := "\x00\x00\x01\x00{{ .name }}\xff\xfe"
+	files := []struct {
+		name string
+		data string
+		mode os.FileMode
+		want string
+	}{
+		{name: "run.sh.tpl", data: "#!/bin/sh\necho {{ .name }}\n", mode: 0o755, want: "#!/bin/sh\necho World\n"},
+		{name: "setup.sh", data: "#!/bin/sh\n", mode: 0o700, want: "#!/bin/sh\n"},
+		{name: "favicon.ico", data: favicon, mode: 0o644, want: favicon},
+		{name: "logo.png.tpl", data: favicon, mode: 0o644, want: favicon},
+		{name: "main.go", data: "package main\nfunc main() {   }\n", mode: 0o644, want: "package main\nfunc main() {   }\n"},
+	}
+
+	processor := New(templateDir, outDir)
+	config := map[string]any{"name": "World"}
+	for _, f := range files {
+		sourcePath := filepath.Join(templateDir, f.name)
+		if err := os.WriteFile(sourcePath, []byte(f.data), f.mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(sourcePath, f.mode); err != nil {
+			t.Fatal(err)
+		}
+
+		targetPath, err := processor.ProcessPath(f.name, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := processor.ProcessFile(sourcePath, targetPath, config); err != nil {
+			t.Fatalf("ProcessFile(%s) error = %v", f.name, err)
+		}
+
+		content, err := os.ReadFile(targetPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != f.want {
+			t.Errorf("%s = %q, want %q", f.name, content, f.want)
+		}
+
+		info, err := os.Stat(targetPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != f.mode {
+			t.Errorf("%s mode = %o, want %o", f.name, info.Mode().Perm(), f.mode)
+		}
+	}
+}
+
+func TestProcessor_AddPartials(t *testing.T) {
+	tmpDir := t.TempDir()
+	templatePath := filepath.Join(tmpDir, "main.go.txt.tpl")
+	targetPath := filepath.Join(tmpDir, "main.go.txt")
+
+	if err := os.WriteFile(templatePath, []byte("[[ template \"license\" . ]]package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	processor := New(tmpDir, tmpDir)
+	processor.SetDelims("[[", "]]")
+	processor.AddPartials(map[string]string{
+		"license": "// Copyright {{ .owner }}\n",
+	})
+
+	if err := processor.ProcessFile(templatePath, targetPath, map[string]any{"owner": "ACME"}); err != nil {
+		t.Fatalf("ProcessFile() error = %v", err)
+	}
+
+	content, err := os.ReadFile(targetPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "// Copyright ACME\npackage main\n"; string(content) != want {
+		t.Errorf("ProcessFile() = %q, want %q", content, want)
+	}
+
+	// Broken partials are reported by name
+	processor.AddPartials(map[string]string{"broken": "{{ if }}"})
+	err = processor.ProcessFile(templatePath, targetPath, map[string]any{"owner": "ACME"})
+	if err == nil || !strings.Contains(err.Error(), "partial broken") {
+		t.Errorf("ProcessFile() error = %v, want error naming the partial", err)
+	}
+}
+
+func TestProcessor_PartialsWithEngine(t *testing.T) {
+	tmpDir := t.TempDir()
+	templatePath := filepath.Join(tmpDir, "main.go.txt.tpl")
+	targetPath := filepath.Join(tmpDir, "main.go.txt")
+
+	processor := New(tmpDir, tmpDir)
+	processor.SetEngine(func(text string, config map[string]any) (string, error) {
+		return text, nil
+	})
+	processor.AddPartials(map[string]string{"license": "// Copyright {{ .owner }}\n"})
+
+	// Only known partials are an error, other {{ }} is output of the engine
+	tests := []struct {
+		text    string
+		wantErr bool
+	}{
+		{"{{ template \"license\" . }}package main\n", true},
+		{"{{- block \"license\" . }}{{ end }}package main\n", true},
+		{"{{ template \"other\" . }}package main\n", false},
+	}
+
+	for _, tt := range tests {
+		if err := os.WriteFile(templatePath, []byte(tt.text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := processor.ProcessFile(templatePath, targetPath, nil)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ProcessFile(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+		}
+		if err != nil && !strings.Contains(err.Error(), "partial license") {
+			t.Errorf("ProcessFile(%q) error = %v, want error naming the partial", tt.text, err)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package util
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 
@@ -12,6 +11,7 @@ import (
 	"go.quinn.io/g/config"
 	"go.quinn.io/g/generator"
 	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
 // ParseConfig parses YAML data into a Config struct and recursively loads included configs
@@ -30,8 +30,32 @@ func (f *FakeAuthorizer) Authenticator(pkgurl string) (auth transport.AuthMethod
 	return nil, false
 }
 
-// loadGenerators loads and merges configs from the Include section
+// LoadGenerators loads and merges configs from the Include section, then
+// resolves the generators they extend and use
 func LoadGenerators(basePath string, include map[string]string) ([]generator.Generator, error) {
+	generators, err := loadIncludes(basePath, include)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := generator.Validate(generators); err != nil {
+		return nil, fmt.Errorf("invalid generators:\n%w", err)
+	}
+
+	if err := generator.ResolveExtends(generators); err != nil {
+		return nil, fmt.Errorf("error resolving extends: %w", err)
+	}
+
+	if err := generator.ResolveUse(generators); err != nil {
+		return nil, fmt.Errorf("error resolving use: %w", err)
+	}
+
+	return generators, nil
+}
+
+// loadIncludes loads the generators of each included config and its own
+// includes
+func loadIncludes(basePath string, include map[string]string) ([]generator.Generator, error) {
 	var allGenerators []generator.Generator
 
 	// Process each included config
@@ -60,33 +84,35 @@ func LoadGenerators(basePath string, include map[string]string) ([]generator.Gen
 		}
 
 		// Namespace the generators from the included config
-		for _, gen := range cfg.Generators {
+		lines := generatorLines(data)
+		for i, gen := range cfg.Generators {
 			cmd := gen.Name
 			if namespace != "" {
 				// Prefix the generator name with the namespace
 				cmd = fmt.Sprintf("%s:%s", namespace, gen.Name)
 			}
 
-			if len(gen.Use) > 0 {
-				if namespace != "" {
-					var use []string
-					for _, u := range gen.Use {
-						use = append(use, fmt.Sprintf("%s:%s", namespace, u))
-					}
-					gen.Use = use
-				}
-				g, err := generator.Find(allGenerators, gen.Use[0])
-				if err != nil {
-					log.Fatalf("Error finding generator: %v", err)
+			if gen.Extends != "" && namespace != "" {
+				gen.Extends = fmt.Sprintf("%s:%s", namespace, gen.Extends)
+			}
+
+			if namespace != "" {
+				for i := range gen.Use {
+					gen.Use[i].Generator = fmt.Sprintf("%s:%s", namespace, gen.Use[i].Generator)
 				}
-				gen.Args = g.Cfg.Args
 			}
 
 			gen := generator.New(gen, cmd, resolvedPath)
+			gen.Namespace = namespace
+			gen.Source = includePath
+			gen.File = configPath
+			if i < len(lines) {
+				gen.Line = lines[i]
+			}
 			allGenerators = append(allGenerators, gen)
 		}
 
-		generators, err := LoadGenerators(resolvedPath, cfg.Include)
+		generators, err := loadIncludes(resolvedPath, cfg.Include)
 		if err != nil {
 			return nil, fmt.Errorf("error loading included configs: %w", err)
 		}
@@ -97,3 +123,31 @@ func LoadGenerators(basePath string, include map[string]string) ([]generator.Gen
 	// return nil
 	return allGenerators, nil
 }
+
+// generatorLines returns the line of each entry in the generators list of
+// a g.yaml file, or nil if it cannot be parsed
+func generatorLines(data []byte) []int {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yamlv3.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "generators" {
+			continue
+		}
+
+		var lines []int
+		for _, entry := range root.Content[i+1].Content {
+			lines = append(lines, entry.Line)
+		}
+		return lines
+	}
+
+	return nil
+}
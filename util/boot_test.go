@@ -0,0 +1,143 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.quinn.io/g/generator"
+)
+
+func TestLoadGenerators(t *testing.T) {
+	rootDir := t.TempDir()
+	libDir := filepath.Join(rootDir, "lib")
+	if err := os.MkdirAll(libDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Generators reference each other before they are defined
+	local := `include:
+  lib: ` + libDir + `
+generators:
+  - name: action
+    use:
+      - route
+      - generator: view
+        with:
+          name: "{{ .funcName }}"
+    args:
+      - funcName
+  - name: view
+    extends: lib:view
+  - name: route
+    args: [method, path]
+`
+	lib := `generators:
+  - name: view
+    args: [name, layout]
+`
+	write(t, filepath.Join(rootDir, "g.yaml"), local)
+	write(t, filepath.Join(libDir, "g.yaml"), lib)
+
+	generators, err := LoadGenerators(rootDir, map[string]string{"": rootDir})
+	if err != nil {
+		t.Fatalf("LoadGenerators() error = %v", err)
+	}
+
+	for _, gen := range generators {
+		if gen.Cmd != "action" {
+			continue
+		}
+		var args []string
+		for _, arg := range gen.Cfg.Args {
+			args = append(args, arg.Name)
+		}
+		if strings.Join(args, " ") != "funcName method path layout" {
+			t.Errorf("action args = %v", args)
+		}
+		if gen.Line != 4 {
+			t.Errorf("action line = %d, want 4", gen.Line)
+		}
+	}
+
+	// Every broken reference is reported with its location
+	write(t, filepath.Join(rootDir, "g.yaml"), `generators:
+  - name: action
+    use: [route, missing]
+  - name: route
+    use: [action]
+  - name: view
+    extends: lib:view
+`)
+
+	_, err = LoadGenerators(rootDir, map[string]string{"": rootDir})
+	if err == nil {
+		t.Fatal("LoadGenerators() expected an error")
+	}
+	configPath := filepath.Join(rootDir, "g.yaml")
+	for _, want := range []string{
+		configPath + ":2: action uses unknown generator missing",
+		configPath + ":6: view extends unknown generator lib:view",
+		configPath + ":2: cycle: action -> route -> action",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadGenerators() error = %v, want %q", err, want)
+		}
+	}
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadGenerators_ExampleAction(t *testing.T) {
+	exampleDir, err := filepath.Abs(filepath.Join("..", "example"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	generators, err := LoadGenerators(exampleDir, map[string]string{"": exampleDir})
+	if err != nil {
+		t.Fatalf("LoadGenerators() error = %v", err)
+	}
+
+	gen, err := generator.Find(generators, "action")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, arg := range gen.Cfg.Args {
+		if arg.Name == "funcName" {
+			t.Errorf("action args = %v, funcName is computed by route", gen.Cfg.Args)
+		}
+	}
+
+	// The route handler calls the view that the view generator defines
+	outDir := t.TempDir()
+	server, err := os.ReadFile(filepath.Join(exampleDir, "internal", "web", "server.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(outDir, "internal", "web"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(outDir, "internal", "web", "server.go"), string(server))
+
+	if _, err := gen.Run(generators, map[string]any{"method": "get", "path": "/posts"}, outDir); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	route, err := os.ReadFile(filepath.Join(outDir, "internal", "routes", "posts.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := os.ReadFile(filepath.Join(outDir, "internal", "views", "posts.templ"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(route), "views.Posts()") || !strings.Contains(string(view), "templ Posts()") {
+		t.Errorf("route and view names differ:\n%s\n%s", route, view)
+	}
+}